@@ -5,21 +5,43 @@ package o
 // index, length of buffer, ring capacity) to keep track of the
 // state.
 //
-// The index wrap-around operation is implemented with modulo division.
+// Since the read index is always smaller than the capacity and the
+// length never exceeds it, every index the ring computes internally
+// is smaller than twice the capacity. The index wrap-around operation
+// on those is implemented with a conditional subtraction instead of a
+// (much slower) modulo division.
 type basicRing struct {
 	cap, read, length uint
+	reserved, peeked  uint
+	gen               uint
+	stats             *Stats
 }
 
 func (r *basicRing) mask(val uint) uint {
+	if val < r.cap {
+		return val
+	}
+	if val-r.cap < r.cap {
+		return val - r.cap
+	}
 	return val % r.cap
 }
 
+// wrap adjusts an index smaller than twice the capacity to fit the
+// ring.
+func (r *basicRing) wrap(val uint) uint {
+	if val >= r.cap {
+		return val - r.cap
+	}
+	return val
+}
+
 func (r *basicRing) start() uint {
 	return r.read
 }
 
 func (r *basicRing) end() uint {
-	return r.mask(r.read + r.length)
+	return r.wrap(r.read + r.length)
 }
 
 func (r *basicRing) capacity() uint {
@@ -27,29 +49,146 @@ func (r *basicRing) capacity() uint {
 }
 
 func (r *basicRing) reset() {
+	r.stats.shifted(r.length)
+	r.read = r.end()
 	r.length = 0
+	r.peeked = 0
+	r.gen++
 }
 
 func (r *basicRing) pushN(n uint) (uint, uint, error) {
 	start := r.length
+	if r.reserved > 0 {
+		idx := r.wrap(r.read + start)
+		return idx, idx, ErrReserved
+	}
 	if n > r.cap-r.length {
-		idx := r.mask(r.read + start)
+		idx := r.wrap(r.read + start)
+		r.stats.failedPush(ErrFull)
 		return idx, idx, ErrFull
 	}
 	r.length += n
-	return r.mask(r.read + start), r.mask(r.read + r.length), nil
+	r.gen++
+	r.stats.pushed(n, r.length)
+	return r.wrap(r.read + start), r.wrap(r.read + r.length), nil
+}
+
+func (r *basicRing) reserveN(n uint) (uint, uint, error) {
+	r.reserved = 0
+	end := r.end()
+	if n > r.cap-r.length {
+		r.stats.failedPush(ErrFull)
+		return end, end, ErrFull
+	}
+	r.reserved = n
+	return end, r.wrap(end + n), nil
+}
+
+func (r *basicRing) commit(n uint) {
+	if n > r.reserved {
+		panic("Commit called with more indexes than were reserved.")
+	}
+	r.reserved = 0
+	r.length += n
+	if n > 0 {
+		r.gen++
+		r.stats.pushed(n, r.length)
+	}
+}
+
+func (r *basicRing) abort() {
+	r.reserved = 0
 }
 
 func (r *basicRing) shiftN(n uint) (uint, uint, error) {
 	start := r.read
+	if r.peeked > 0 {
+		return start, start, ErrPeeked
+	}
 	if n > r.size() {
+		r.stats.failedShift(ErrEmpty)
 		return start, start, ErrEmpty
 	}
 	r.length -= n
-	r.read = r.mask(r.read + n)
+	r.read = r.wrap(r.read + n)
+	r.gen++
+	r.stats.shifted(n)
 	return start, r.read, nil
 }
 
+func (r *basicRing) peekN(n uint) (uint, uint, error) {
+	r.peeked = 0
+	if n > r.length {
+		r.stats.failedShift(ErrEmpty)
+		return r.read, r.read, ErrEmpty
+	}
+	r.peeked = n
+	return r.read, r.wrap(r.read + n), nil
+}
+
+func (r *basicRing) release(n uint) {
+	if n > r.peeked {
+		panic("Release called with more indexes than were peeked.")
+	}
+	r.peeked = 0
+	r.length -= n
+	r.read = r.wrap(r.read + n)
+	if n > 0 {
+		r.gen++
+		r.stats.shifted(n)
+	}
+}
+
+func (r *basicRing) unshiftN(n uint) (uint, uint, error) {
+	if r.peeked > 0 {
+		return r.read, r.read, ErrPeeked
+	}
+	if n > r.cap-r.length-r.reserved {
+		r.stats.failedPush(ErrFull)
+		return r.read, r.read, ErrFull
+	}
+	end := r.read
+	r.read = r.wrap(r.read + r.cap - n)
+	r.length += n
+	r.gen++
+	r.stats.pushed(n, r.length)
+	return r.read, end, nil
+}
+
+func (r *basicRing) popN(n uint) (uint, uint, error) {
+	end := r.end()
+	if r.reserved > 0 {
+		return end, end, ErrReserved
+	}
+	if n > r.length {
+		r.stats.failedShift(ErrEmpty)
+		return end, end, ErrEmpty
+	}
+	if n > r.length-r.peeked {
+		return end, end, ErrPeeked
+	}
+	r.length -= n
+	r.gen++
+	r.stats.shifted(n)
+	return r.end(), end, nil
+}
+
+func (r *basicRing) outstanding() (uint, uint) {
+	return r.reserved, r.peeked
+}
+
+func (r *basicRing) generation() uint {
+	return r.gen
+}
+
+func (r *basicRing) statistics() *Stats {
+	return r.stats
+}
+
+func (r *basicRing) setStatistics(s *Stats) {
+	r.stats = s
+}
+
 func (r *basicRing) full() bool {
 	return r.cap == r.length
 }
@@ -40,6 +40,16 @@ func TestShift(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestBasicMask(t *testing.T) {
+	r := NewRing(BasicN)
+	for _, val := range []uint{0, 1, BasicN - 1, BasicN, BasicN + 1, 2*BasicN - 1, 2 * BasicN, 7*BasicN + 3, ^uint(0)} {
+		assert.Equal(t, val%BasicN, r.Mask(val), "Mask(%d)", val)
+	}
+
+	huge := NewRing(^uint(0) - 1)
+	assert.Equal(t, uint(1), huge.Mask(^uint(0)))
+}
+
 func BenchmarkBasicRing(b *testing.B) {
 	r := NewRing(uint(b.N))
 	var i uint
@@ -0,0 +1,71 @@
+package o_test
+
+import (
+	"testing"
+
+	"github.com/antifuchs/o"
+)
+
+// Capacities for comparing the two accounting algorithms: a
+// power-of-two ring and a slightly smaller one that isn't.
+var benchmarkRings = []struct {
+	name string
+	cap  uint
+}{
+	{"mask/1024", 1024},
+	{"basic/1000", 1000},
+}
+
+func BenchmarkPushShift(b *testing.B) {
+	for _, bench := range benchmarkRings {
+		b.Run(bench.name, func(b *testing.B) {
+			ring := o.NewRing(bench.cap)
+			for i := 0; i < b.N; i++ {
+				ring.Push()
+				ring.Shift()
+			}
+		})
+	}
+}
+
+func BenchmarkPushNShiftN(b *testing.B) {
+	for _, bench := range benchmarkRings {
+		b.Run(bench.name, func(b *testing.B) {
+			ring := o.NewRing(bench.cap)
+			for i := 0; i < b.N; i++ {
+				ring.PushN(7)
+				ring.ShiftN(7)
+			}
+		})
+	}
+}
+
+func BenchmarkMask(b *testing.B) {
+	for _, bench := range benchmarkRings {
+		b.Run(bench.name, func(b *testing.B) {
+			ring := o.NewRing(bench.cap)
+			var sum uint
+			for i := 0; i < b.N; i++ {
+				sum += ring.Mask(uint(i) & 2047)
+			}
+			_ = sum
+		})
+	}
+}
+
+func BenchmarkConcretePushShift(b *testing.B) {
+	b.Run("MaskRing/1024", func(b *testing.B) {
+		ring, _ := o.NewMaskRing(1024)
+		for i := 0; i < b.N; i++ {
+			ring.Push()
+			ring.Shift()
+		}
+	})
+	b.Run("ModRing/1000", func(b *testing.B) {
+		ring, _ := o.NewModRing(1000)
+		for i := 0; i < b.N; i++ {
+			ring.Push()
+			ring.Shift()
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package o
+
+// BipRing provides accounting for a bip-buffer (bipartite buffer): A
+// ring buffer that only ever hands out contiguous regions for
+// writing, which is what codecs and system calls that fill a single
+// slice need.
+//
+// When a reservation does not fit between the write end and the end
+// of the buffer, BipRing places it at the start of the buffer
+// instead, and skips the gap at the end: It remembers where the
+// readable data stops (the watermark), so Inspect and ShiftN never
+// return indexes from the skipped region. The cost of this is that a
+// BipRing can not always use its full capacity for one reservation.
+//
+// Like Ring, BipRing is not safe for concurrent use.
+type BipRing struct {
+	cap, read, write uint
+
+	// watermark is the end of the readable data in the buffer's
+	// tail while the write end has wrapped around (write < read);
+	// it equals cap otherwise.
+	watermark uint
+
+	reserved Range
+	// wrapping is true if the outstanding reservation starts at
+	// the beginning of the buffer, skipping its tail.
+	wrapping bool
+}
+
+// NewBipRing returns a BipRing with the given capacity.
+func NewBipRing(cap uint) *BipRing {
+	return &BipRing{cap: cap, watermark: cap}
+}
+
+// inverted returns whether the write end has wrapped around to the
+// start of the buffer while the read end has not.
+func (b *BipRing) inverted() bool {
+	return b.write < b.read
+}
+
+// Capacity returns the number of indexes in the buffer.
+func (b *BipRing) Capacity() uint {
+	return b.cap
+}
+
+// Size returns the number of readable elements in the buffer.
+func (b *BipRing) Size() uint {
+	if b.inverted() {
+		return b.watermark - b.read + b.write
+	}
+	return b.write - b.read
+}
+
+// Empty returns whether the buffer has zero readable elements.
+func (b *BipRing) Empty() bool {
+	return b.read == b.write
+}
+
+// ReserveContiguous returns a single range of count free indexes,
+// without making them readable. Once the elements at the reserved
+// indexes are filled, Commit makes (some of) them readable; Abort
+// gives them all back. Calling ReserveContiguous again replaces the
+// previous reservation.
+//
+// If there is no contiguous free range of count indexes, nothing is
+// reserved and ReserveContiguous returns ErrFull.
+func (b *BipRing) ReserveContiguous(count uint) (Range, error) {
+	b.reserved, b.wrapping = Range{}, false
+	if b.Empty() {
+		// Nothing to preserve; start over at the beginning to
+		// make the most room.
+		b.read, b.write, b.watermark = 0, 0, b.cap
+	}
+	switch {
+	case b.inverted():
+		// Writes must stay strictly behind the read end, or the
+		// buffer would look empty.
+		if count >= b.read-b.write {
+			return Range{}, ErrFull
+		}
+	case count <= b.cap-b.write:
+		// Fits into the tail.
+	case count < b.read:
+		// Skip the tail and wrap around to the start.
+		b.wrapping = true
+		b.reserved = Range{Start: 0, End: count}
+		return b.reserved, nil
+	default:
+		return Range{}, ErrFull
+	}
+	b.reserved = Range{Start: b.write, End: b.write + count}
+	return b.reserved, nil
+}
+
+// Commit makes the first count indexes of the outstanding reservation
+// readable, and releases the rest of the reservation.
+//
+// Commit panics if count exceeds the number of reserved indexes.
+func (b *BipRing) Commit(count uint) {
+	if count > b.reserved.Length() {
+		panic("Commit called with more indexes than were reserved.")
+	}
+	wrapping := b.wrapping
+	b.reserved, b.wrapping = Range{}, false
+	if count == 0 {
+		return
+	}
+	if !wrapping {
+		b.write += count
+		return
+	}
+	b.watermark, b.write = b.write, count
+	if b.read == b.watermark {
+		// Everything in the tail was read already.
+		b.read, b.watermark = 0, b.cap
+	}
+}
+
+// Abort releases the outstanding reservation without making any of
+// its indexes readable.
+func (b *BipRing) Abort() {
+	b.reserved, b.wrapping = Range{}, false
+}
+
+// Inspect returns a set of indexes that represent the bounds of the
+// readable elements in the buffer, in FIFO order. The second range
+// is only non-empty while the write end has wrapped around to the
+// start of the buffer; the skipped region between the watermark and
+// the end of the buffer is never part of either range.
+func (b *BipRing) Inspect() (first, second Range) {
+	if b.inverted() {
+		return Range{Start: b.read, End: b.watermark}, Range{Start: 0, End: b.write}
+	}
+	return Range{Start: b.read, End: b.write}, Range{}
+}
+
+// ShiftN bulk-"read"s count indexes from the start of the buffer and
+// returns ranges covering the indexes that were removed.
+//
+// If the buffer holds fewer elements than requested, ShiftN reads
+// nothing and returns ErrEmpty.
+func (b *BipRing) ShiftN(count uint) (first, second Range, err error) {
+	if count > b.Size() {
+		return first, second, ErrEmpty
+	}
+	if !b.inverted() || count < b.watermark-b.read {
+		first = Range{Start: b.read, End: b.read + count}
+		b.read += count
+		return
+	}
+	first = Range{Start: b.read, End: b.watermark}
+	rest := count - first.Length()
+	second = Range{Start: 0, End: rest}
+	b.read, b.watermark = rest, b.cap
+	return
+}
+
+// Shift "reads" the oldest element from the buffer and returns its
+// index.
+//
+// Returns ErrEmpty if the buffer has no elements to read.
+func (b *BipRing) Shift() (uint, error) {
+	first, _, err := b.ShiftN(1)
+	return first.Start, err
+}
+
+// Consume resets the buffer to its empty state, returning the ranges
+// of the elements that were readable before, like Inspect.
+func (b *BipRing) Consume() (first, second Range) {
+	first, second = b.Inspect()
+	b.read, b.watermark = b.write, b.cap
+	return
+}
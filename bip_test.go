@@ -0,0 +1,188 @@
+package o_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBipRingSkipsTail(t *testing.T) {
+	t.Parallel()
+	b := o.NewBipRing(10)
+	rg, err := b.ReserveContiguous(7)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 0, End: 7}, rg)
+	b.Commit(7)
+	_, _, err = b.ShiftN(4)
+	require.NoError(t, err)
+
+	// 3 free indexes in the tail, 4 at the start:
+	_, err = b.ReserveContiguous(5)
+	assert.Equal(t, o.ErrFull, err)
+	_, err = b.ReserveContiguous(4)
+	assert.Equal(t, o.ErrFull, err, "writes must stay behind the read end")
+	rg, err = b.ReserveContiguous(3)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 7, End: 10}, rg)
+	b.Abort()
+
+	rg, err = b.ReserveContiguous(2)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 7, End: 9}, rg)
+	b.Commit(1)
+	rg, err = b.ReserveContiguous(3)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 0, End: 3}, rg, "tail is too short, so wrap")
+	b.Commit(2)
+
+	first, second := b.Inspect()
+	assert.Equal(t, o.Range{Start: 4, End: 8}, first, "skipped tail is not readable")
+	assert.Equal(t, o.Range{Start: 0, End: 2}, second)
+	assert.Equal(t, uint(6), b.Size())
+
+	first, second, err = b.ShiftN(5)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 4, End: 8}, first)
+	assert.Equal(t, o.Range{Start: 0, End: 1}, second)
+	idx, err := b.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), idx)
+	_, err = b.Shift()
+	assert.Equal(t, o.ErrEmpty, err)
+
+	assert.Panics(t, func() { b.Commit(1) })
+}
+
+func TestBipRingConsume(t *testing.T) {
+	t.Parallel()
+	b := o.NewBipRing(10)
+	first, second := b.Consume()
+	assert.True(t, first.Empty() && second.Empty(), "nothing to consume")
+
+	_, err := b.ReserveContiguous(8)
+	require.NoError(t, err)
+	b.Commit(8)
+	_, _, err = b.ShiftN(6)
+	require.NoError(t, err)
+	_, err = b.ReserveContiguous(4)
+	require.NoError(t, err)
+	b.Commit(4)
+
+	first, second = b.Consume()
+	assert.Equal(t, o.Range{Start: 6, End: 8}, first, "skipped tail is not consumed")
+	assert.Equal(t, o.Range{Start: 0, End: 4}, second)
+	assert.True(t, b.Empty())
+	assert.Equal(t, uint(0), b.Size())
+	first, second = b.Inspect()
+	assert.True(t, first.Empty() && second.Empty())
+	_, err = b.Shift()
+	assert.Equal(t, o.ErrEmpty, err)
+
+	// The whole buffer is free for a contiguous write again:
+	rg, err := b.ReserveContiguous(10)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 0, End: 10}, rg)
+	b.Commit(10)
+	first, second = b.Consume()
+	assert.Equal(t, o.Range{Start: 0, End: 10}, first)
+	assert.Empty(t, second)
+}
+
+// bipModel mirrors operations on a BipRing in a plain slice.
+type bipModel struct {
+	bip   *o.BipRing
+	buf   []int
+	elts  []int
+	label int
+	res   o.Range
+}
+
+func (m *bipModel) values(first, second o.Range) []int {
+	vals := []int{}
+	for _, rg := range []o.Range{first, second} {
+		for i := rg.Start; i < rg.End; i++ {
+			vals = append(vals, m.buf[i])
+		}
+	}
+	return vals
+}
+
+func (m *bipModel) apply(op, n uint) string {
+	switch op {
+	case 0:
+		first, second := m.bip.Inspect()
+		rg, err := m.bip.ReserveContiguous(n)
+		m.res = rg
+		if err != nil {
+			if uint(len(m.elts))+n <= m.bip.Capacity()/2 && n > 0 && len(m.elts) == 0 {
+				return fmt.Sprintf("could not reserve %d in an empty buffer", n)
+			}
+			return ""
+		}
+		if rg.Length() != n || rg.End > m.bip.Capacity() {
+			return fmt.Sprintf("reserved %v for %d", rg, n)
+		}
+		for _, occ := range []o.Range{first, second} {
+			if !occ.Empty() && rg.Start < occ.End && occ.Start < rg.End {
+				return fmt.Sprintf("reserved %v overlaps occupied %v", rg, occ)
+			}
+		}
+		for i := rg.Start; i < rg.End; i++ {
+			m.label++
+			m.buf[i] = m.label
+		}
+	case 1:
+		k := n
+		if k > m.res.Length() {
+			k = m.res.Length()
+		}
+		m.elts = append(m.elts, m.buf[m.res.Start:m.res.Start+k]...)
+		m.bip.Commit(k)
+		m.res = o.Range{}
+	case 2:
+		first, second, err := m.bip.ShiftN(n)
+		if (err == nil) != (n <= uint(len(m.elts))) {
+			return fmt.Sprintf("ShiftN(%d) of %d: %v", n, len(m.elts), err)
+		}
+		if err == nil {
+			if got := m.values(first, second); !reflect.DeepEqual(got, m.elts[:n]) {
+				return fmt.Sprintf("shifted %v, expected %v", got, m.elts[:n])
+			}
+			m.elts = m.elts[n:]
+		}
+	}
+	if got := m.values(m.bip.Inspect()); !reflect.DeepEqual(got, m.elts) {
+		return fmt.Sprintf("readable %v, expected %v", got, m.elts)
+	}
+	if m.bip.Size() != uint(len(m.elts)) {
+		return fmt.Sprintf("size %d, expected %d", m.bip.Size(), len(m.elts))
+	}
+	return ""
+}
+
+func TestPropBipRing(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("BipRing behaves like a queue with contiguous reservations", prop.ForAll(
+		func(cap uint, ops []uint) string {
+			m := &bipModel{bip: o.NewBipRing(cap), buf: make([]int, cap), elts: []int{}}
+			for i, op := range ops {
+				if msg := m.apply(op%3, op/3); msg != "" {
+					return fmt.Sprintf("op %d (%d %d): %s", i, op%3, op/3, msg)
+				}
+			}
+			return ""
+		},
+		gen.UIntRange(1, 20).WithLabel("capacity"),
+		gen.SliceOf(gen.UIntRange(0, 3*12)).WithLabel("operations"),
+	))
+	properties.TestingRun(t)
+}
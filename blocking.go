@@ -0,0 +1,183 @@
+package o
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Blocking provides ring buffer accounting that any number of
+// goroutines can share, and on which producers and consumers can
+// wait for free or readable indexes instead of polling.
+//
+// A producer calls PushWait to reserve free indexes, fills them and
+// then makes them readable with Commit. A consumer calls ShiftWait to
+// peek at readable indexes, reads them and then frees them with
+// Release. Only one reservation and one peek can be outstanding at a
+// time; further PushWait (or ShiftWait) calls wait until the
+// outstanding one is committed or aborted (or released).
+//
+// Once the producing side calls Close, consumers can drain the
+// remaining elements, after which ShiftWait returns io.EOF.
+type Blocking struct {
+	mu      sync.Mutex
+	r       Ring
+	closed  bool
+	changed chan struct{}
+}
+
+// NewBlocking returns a Blocking ring with the given capacity.
+func NewBlocking(cap uint) *Blocking {
+	return &Blocking{r: NewRing(cap), changed: make(chan struct{})}
+}
+
+// signal wakes up all waiting goroutines. It must be called with
+// b.mu held.
+func (b *Blocking) signal() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// wait releases b.mu and sleeps until the ring changes or ctx is
+// done.
+func (b *Blocking) wait(ctx context.Context) error {
+	changed := b.changed
+	b.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-changed:
+		return nil
+	}
+}
+
+// Capacity returns the number of continuous indexes that can be
+// represented on the ring.
+func (b *Blocking) Capacity() uint {
+	return b.r.Capacity()
+}
+
+// Size returns the number of readable elements on the ring.
+func (b *Blocking) Size() uint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.r.Size()
+}
+
+// PushWait reserves count free indexes on the ring, waiting until
+// enough of them are free and no other reservation is outstanding.
+// The reserved indexes become readable when they are passed to
+// Commit.
+//
+// If ctx is done before the indexes could be reserved, PushWait
+// returns ctx.Err(). It returns io.ErrClosedPipe if the ring is
+// closed, and ErrFull if count exceeds the ring's capacity.
+func (b *Blocking) PushWait(ctx context.Context, count uint) (first, second Range, err error) {
+	if count > b.r.Capacity() {
+		return first, second, ErrFull
+	}
+	b.mu.Lock()
+	for {
+		if b.closed {
+			b.mu.Unlock()
+			return first, second, io.ErrClosedPipe
+		}
+		if reserved, _ := b.r.outstanding(); reserved == 0 {
+			first, second, err = b.r.Reserve(count)
+			if err == nil {
+				b.mu.Unlock()
+				return
+			}
+		}
+		if err = b.wait(ctx); err != nil {
+			return Range{}, Range{}, err
+		}
+		b.mu.Lock()
+	}
+}
+
+// Commit makes the first count indexes reserved by PushWait readable
+// and ends the reservation, waking up waiting consumers.
+//
+// Commit panics if count exceeds the number of reserved indexes.
+func (b *Blocking) Commit(count uint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.r.Commit(count)
+	b.signal()
+}
+
+// Abort ends the reservation made by PushWait without making any of
+// its indexes readable.
+func (b *Blocking) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.r.Abort()
+	b.signal()
+}
+
+// ShiftWait peeks at the count oldest readable indexes on the ring,
+// waiting until enough of them are readable and no other peek is
+// outstanding. The peeked indexes become free when they are passed
+// to Release.
+//
+// Once the ring is closed, ShiftWait no longer waits: If fewer than
+// count elements remain, it peeks at all of them, and if none
+// remain, it returns io.EOF.
+//
+// If ctx is done before the indexes could be peeked at, ShiftWait
+// returns ctx.Err(). It returns ErrEmpty if count exceeds the ring's
+// capacity and the ring is not closed.
+func (b *Blocking) ShiftWait(ctx context.Context, count uint) (first, second Range, err error) {
+	b.mu.Lock()
+	for {
+		if _, peeked := b.r.outstanding(); peeked == 0 {
+			n := count
+			if b.closed {
+				size := b.r.Size()
+				if size == 0 {
+					b.mu.Unlock()
+					return first, second, io.EOF
+				}
+				if n > size {
+					n = size
+				}
+			} else if n > b.r.Capacity() {
+				b.mu.Unlock()
+				return first, second, ErrEmpty
+			}
+			first, second, err = b.r.PeekN(n)
+			if err == nil {
+				b.mu.Unlock()
+				return
+			}
+		}
+		if err = b.wait(ctx); err != nil {
+			return Range{}, Range{}, err
+		}
+		b.mu.Lock()
+	}
+}
+
+// Release frees the first count indexes peeked at by ShiftWait and
+// ends the peek, waking up waiting producers.
+//
+// Release panics if count exceeds the number of peeked indexes.
+func (b *Blocking) Release(count uint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.r.Release(count)
+	b.signal()
+}
+
+// Close marks the ring as closed: PushWait fails from now on, and
+// ShiftWait returns io.EOF once the remaining elements are drained.
+// All waiting goroutines are woken up. An outstanding reservation can
+// still be committed.
+func (b *Blocking) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.signal()
+	return nil
+}
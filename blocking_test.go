@@ -0,0 +1,168 @@
+package o_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockingWakesConsumer(t *testing.T) {
+	t.Parallel()
+	b := o.NewBlocking(4)
+	buf := make([]int, b.Capacity())
+	got := make(chan []int)
+	go func() {
+		first, second, err := b.ShiftWait(context.Background(), 3)
+		if err != nil {
+			got <- nil
+			return
+		}
+		vals := append(append([]int{}, buf[first.Start:first.End]...), buf[second.Start:second.End]...)
+		b.Release(first.Length() + second.Length())
+		got <- vals
+	}()
+
+	for i := 1; i <= 3; i++ {
+		first, _, err := b.PushWait(context.Background(), 1)
+		require.NoError(t, err)
+		buf[first.Start] = i
+		b.Commit(1)
+	}
+	assert.Equal(t, []int{1, 2, 3}, <-got)
+	assert.Equal(t, uint(0), b.Size())
+}
+
+func TestBlockingWakesProducer(t *testing.T) {
+	t.Parallel()
+	b := o.NewBlocking(2)
+	_, _, err := b.PushWait(context.Background(), 2)
+	require.NoError(t, err)
+	b.Commit(2)
+
+	pushed := make(chan error)
+	go func() {
+		_, _, err := b.PushWait(context.Background(), 1)
+		if err == nil {
+			b.Commit(1)
+		}
+		pushed <- err
+	}()
+	select {
+	case err := <-pushed:
+		t.Fatalf("PushWait returned on a full ring: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	_, _, err = b.ShiftWait(context.Background(), 1)
+	require.NoError(t, err)
+	b.Release(1)
+	assert.NoError(t, <-pushed)
+	assert.Equal(t, uint(2), b.Size())
+}
+
+func TestBlockingAbortWakesProducer(t *testing.T) {
+	t.Parallel()
+	b := o.NewBlocking(4)
+	first, _, err := b.PushWait(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 0, End: 3}, first)
+
+	pushed := make(chan o.Range)
+	go func() {
+		first, _, err := b.PushWait(context.Background(), 2)
+		if err != nil {
+			pushed <- o.Range{}
+			return
+		}
+		b.Commit(2)
+		pushed <- first
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("PushWait returned while another reservation was outstanding")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	b.Abort()
+	assert.Equal(t, o.Range{Start: 0, End: 2}, <-pushed, "aborted indexes are free again")
+	assert.Equal(t, uint(2), b.Size())
+}
+
+func TestBlockingCancel(t *testing.T) {
+	t.Parallel()
+	b := o.NewBlocking(2)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := b.ShiftWait(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	_, _, err = b.PushWait(context.Background(), 3)
+	assert.Equal(t, o.ErrFull, err)
+	_, _, err = b.ShiftWait(context.Background(), 3)
+	assert.Equal(t, o.ErrEmpty, err)
+
+	_, _, err = b.PushWait(context.Background(), 2)
+	require.NoError(t, err)
+	b.Commit(2)
+	_, _, err = b.PushWait(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestBlockingClose(t *testing.T) {
+	t.Parallel()
+	b := o.NewBlocking(4)
+	eof := make(chan error)
+	go func() {
+		_, _, err := b.ShiftWait(context.Background(), 4)
+		for err == nil {
+			b.Release(1)
+			_, _, err = b.ShiftWait(context.Background(), 4)
+		}
+		eof <- err
+	}()
+
+	_, _, err := b.PushWait(context.Background(), 3)
+	require.NoError(t, err)
+	b.Commit(3)
+	require.NoError(t, b.Close())
+	assert.Equal(t, io.EOF, <-eof)
+
+	_, _, err = b.PushWait(context.Background(), 1)
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+func TestBlockingConcurrent(t *testing.T) {
+	t.Parallel()
+	const total = 1000
+	b := o.NewBlocking(8)
+	buf := make([]int, b.Capacity())
+
+	for p := 0; p < 2; p++ {
+		go func() {
+			for i := 0; i < total/2; i++ {
+				first, _, err := b.PushWait(context.Background(), 1)
+				if err != nil {
+					panic(err)
+				}
+				buf[first.Start] = 1
+				b.Commit(1)
+			}
+		}()
+	}
+
+	sum := 0
+	for sum < total {
+		first, second, err := b.ShiftWait(context.Background(), 1)
+		require.NoError(t, err)
+		assert.True(t, second.Empty())
+		sum += buf[first.Start]
+		buf[first.Start] = 0
+		b.Release(1)
+	}
+	assert.Equal(t, total, sum)
+}
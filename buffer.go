@@ -0,0 +1,146 @@
+package o
+
+// Buffer is a ring buffer of values of type T. It owns its backing
+// slice and uses a Ring for its accounting, so callers deal in
+// values instead of indexes.
+//
+// Slots that become unoccupied (through shifting or being
+// overwritten) are reset to T's zero value, so that anything they
+// referenced can be garbage-collected.
+//
+// Like Ring, Buffer is not safe for concurrent use.
+type Buffer[T any] struct {
+	r   Ring
+	buf []T
+}
+
+// NewBuffer returns a Buffer that can hold up to cap elements.
+func NewBuffer[T any](cap uint) *Buffer[T] {
+	return &Buffer[T]{r: NewRing(cap), buf: make([]T, cap)}
+}
+
+// Capacity returns the number of elements that fit into the Buffer.
+func (b *Buffer[T]) Capacity() uint {
+	return b.r.Capacity()
+}
+
+// Size returns the number of elements in the Buffer.
+func (b *Buffer[T]) Size() uint {
+	return b.r.Size()
+}
+
+// Empty returns whether the Buffer holds no elements.
+func (b *Buffer[T]) Empty() bool {
+	return b.r.Empty()
+}
+
+// Full returns whether the Buffer is filled to capacity.
+func (b *Buffer[T]) Full() bool {
+	return b.r.Full()
+}
+
+// Push appends val to the Buffer.
+//
+// Returns ErrFull if the Buffer is filled to capacity.
+func (b *Buffer[T]) Push(val T) error {
+	i, err := b.r.Push()
+	if err != nil {
+		return err
+	}
+	b.buf[i] = val
+	return nil
+}
+
+// ForcePush appends val to the Buffer, discarding the oldest element
+// if the Buffer is full. A Buffer of capacity 0 discards val itself.
+//
+// See Ring.ForcePush for the caveats of discarding data.
+func (b *Buffer[T]) ForcePush(val T) {
+	if i, err := b.r.ForcePush(); err == nil {
+		b.buf[i] = val
+	}
+}
+
+// PushN appends all of vals to the Buffer.
+//
+// If the Buffer can not accommodate all elements, PushN appends
+// nothing and returns ErrFull.
+func (b *Buffer[T]) PushN(vals []T) error {
+	first, second, err := b.r.PushN(uint(len(vals)))
+	if err != nil {
+		return err
+	}
+	n := copy(b.buf[first.Start:first.End], vals)
+	copy(b.buf[second.Start:second.End], vals[n:])
+	return nil
+}
+
+// Shift removes the oldest element from the Buffer and returns it.
+//
+// Returns ErrEmpty if the Buffer holds no elements.
+func (b *Buffer[T]) Shift() (T, error) {
+	var zero T
+	i, err := b.r.Shift()
+	if err != nil {
+		return zero, err
+	}
+	val := b.buf[i]
+	b.buf[i] = zero
+	return val, nil
+}
+
+// ShiftN removes the count oldest elements from the Buffer and
+// returns them in a newly-allocated slice, oldest first.
+//
+// If the Buffer holds fewer than count elements, ShiftN removes
+// nothing and returns ErrEmpty.
+func (b *Buffer[T]) ShiftN(count uint) ([]T, error) {
+	first, second, err := b.r.ShiftN(count)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]T, 0, count)
+	vals = append(vals, b.buf[first.Start:first.End]...)
+	vals = append(vals, b.buf[second.Start:second.End]...)
+	b.clear(first, second)
+	return vals, nil
+}
+
+// Peek returns the oldest element in the Buffer without removing
+// it.
+//
+// Returns ErrEmpty if the Buffer holds no elements.
+func (b *Buffer[T]) Peek() (T, error) {
+	if b.r.Size() == 0 {
+		// Rings of capacity 0 are never Empty.
+		var zero T
+		return zero, ErrEmpty
+	}
+	return b.buf[b.r.start()], nil
+}
+
+// Inspect returns the elements held in the Buffer, oldest first, as
+// two slices sharing the Buffer's backing storage. As with
+// Ring.Inspect, the second slice may be empty.
+//
+// The returned slices are only valid until the next modification of
+// the Buffer.
+func (b *Buffer[T]) Inspect() (first, second []T) {
+	f, s := b.r.Inspect()
+	return b.buf[f.Start:f.End], b.buf[s.Start:s.End]
+}
+
+// Reset discards all elements in the Buffer.
+func (b *Buffer[T]) Reset() {
+	b.clear(b.r.Consume())
+}
+
+func (b *Buffer[T]) clear(first, second Range) {
+	var zero T
+	for i := first.Start; i < first.End; i++ {
+		b.buf[i] = zero
+	}
+	for i := second.Start; i < second.End; i++ {
+		b.buf[i] = zero
+	}
+}
@@ -0,0 +1,21 @@
+package o_test
+
+import (
+	"fmt"
+
+	"github.com/antifuchs/o"
+)
+
+// A buffer of the last three log lines seen.
+func ExampleBuffer() {
+	lines := o.NewBuffer[string](3)
+	for _, line := range []string{"one", "two", "three", "four"} {
+		lines.ForcePush(line)
+	}
+	for !lines.Empty() {
+		line, _ := lines.Shift()
+		fmt.Print(line, " ")
+	}
+	// Output:
+	// two three four
+}
@@ -0,0 +1,110 @@
+package o
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferPushShift(t *testing.T) {
+	t.Parallel()
+	for _, cap := range []uint{4, 5} {
+		b := NewBuffer[int](cap)
+		for i := 0; i < int(cap); i++ {
+			require.NoError(t, b.Push(i))
+		}
+		assert.Equal(t, ErrFull, b.Push(99))
+		assert.True(t, b.Full())
+
+		for i := 0; i < int(cap); i++ {
+			val, err := b.Shift()
+			require.NoError(t, err)
+			assert.Equal(t, i, val)
+		}
+		_, err := b.Shift()
+		assert.Equal(t, ErrEmpty, err)
+		assert.True(t, b.Empty())
+	}
+}
+
+func TestBufferPushNShiftN(t *testing.T) {
+	t.Parallel()
+	b := NewBuffer[string](5)
+	require.NoError(t, b.PushN([]string{"a", "b", "c"}))
+	vals, err := b.ShiftN(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, vals)
+
+	// This wraps around the end of the backing slice:
+	require.NoError(t, b.PushN([]string{"d", "e", "f", "g"}))
+	assert.Equal(t, ErrFull, b.PushN([]string{"h"}))
+
+	first, second := b.Inspect()
+	assert.Equal(t, []string{"c", "d", "e"}, first)
+	assert.Equal(t, []string{"f", "g"}, second)
+
+	_, err = b.ShiftN(6)
+	assert.Equal(t, ErrEmpty, err)
+	vals, err = b.ShiftN(5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d", "e", "f", "g"}, vals)
+}
+
+func TestBufferForcePushPeek(t *testing.T) {
+	t.Parallel()
+	b := NewBuffer[int](3)
+	_, err := b.Peek()
+	assert.Equal(t, ErrEmpty, err)
+
+	for i := 0; i < 5; i++ {
+		b.ForcePush(i)
+	}
+	val, err := b.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, 2, val)
+	assert.Equal(t, uint(3), b.Size())
+
+	first, second := b.Inspect()
+	assert.Equal(t, []int{2, 3, 4}, append(first, second...))
+}
+
+func TestBufferZeroCapacity(t *testing.T) {
+	t.Parallel()
+	b := NewBuffer[int](0)
+	assert.Equal(t, uint(0), b.Capacity())
+	assert.Equal(t, ErrFull, b.Push(1))
+	assert.Equal(t, ErrFull, b.PushN([]int{1}))
+	b.ForcePush(1)
+	assert.Equal(t, uint(0), b.Size())
+	assert.True(t, b.Full())
+	_, err := b.Shift()
+	assert.Equal(t, ErrEmpty, err)
+	_, err = b.ShiftN(1)
+	assert.Equal(t, ErrEmpty, err)
+	_, err = b.Peek()
+	assert.Equal(t, ErrEmpty, err)
+	b.Reset()
+	first, second := b.Inspect()
+	assert.Empty(t, first)
+	assert.Empty(t, second)
+}
+
+func TestBufferZeroesVacatedSlots(t *testing.T) {
+	t.Parallel()
+	b := NewBuffer[*int](2)
+	one, two, three := 1, 2, 3
+	require.NoError(t, b.PushN([]*int{&one, &two}))
+	_, err := b.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, []*int{nil, &two}, b.buf)
+
+	require.NoError(t, b.Push(&three))
+	_, err = b.ShiftN(2)
+	require.NoError(t, err)
+	assert.Equal(t, []*int{nil, nil}, b.buf)
+
+	require.NoError(t, b.Push(&one))
+	b.Reset()
+	assert.Equal(t, []*int{nil, nil}, b.buf)
+}
@@ -0,0 +1,87 @@
+package o
+
+import "fmt"
+
+// Check verifies that the ring's accounting is consistent, and
+// returns an error wrapping ErrInvalid describing the first
+// inconsistency it finds. It checks that:
+//
+//   - the ring holds no more elements than its capacity, and no more
+//     elements are reserved than are free and no more are peeked than
+//     are occupied,
+//   - the read and write ends are valid indexes,
+//   - Empty and Full agree with Size,
+//   - the ranges returned by Inspect start at the read end, stay
+//     within the capacity and cover exactly Size indexes.
+//
+// Rings of capacity 0 are always full and never empty, and hold no
+// elements.
+//
+// Check does not modify the ring. It is meant for tests and debug
+// builds of data structures that are built on a Ring.
+func (r Ring) Check() error {
+	return stateOf(r.ringBackend).check()
+}
+
+// state is a snapshot of a ring's accounting, which String, Format
+// and Check describe. Ring and the concrete ring types fill it in
+// from their backends.
+type state struct {
+	cap, size, start, end uint
+	reserved, peeked      uint
+	full, empty           bool
+	first, second         Range
+}
+
+func stateOf[B ringBackend](b B) state {
+	s := state{
+		cap: b.capacity(), size: b.size(), start: b.start(), end: b.end(),
+		full: b.full(), empty: b.empty(),
+	}
+	s.reserved, s.peeked = b.outstanding()
+	s.first, s.second = inspect(b)
+	return s
+}
+
+func (s state) check() error {
+	cap, size := s.cap, s.size
+	if cap == 0 {
+		if size != 0 || !s.full || s.empty {
+			return fmt.Errorf("%w: ring of capacity 0 has size %d, full=%t, empty=%t",
+				ErrInvalid, size, s.full, s.empty)
+		}
+		return nil
+	}
+	if size > cap {
+		return fmt.Errorf("%w: size %d exceeds capacity %d", ErrInvalid, size, cap)
+	}
+	if s.reserved > cap-size {
+		return fmt.Errorf("%w: %d indexes reserved, but only %d are free", ErrInvalid, s.reserved, cap-size)
+	}
+	if s.peeked > size {
+		return fmt.Errorf("%w: %d indexes peeked, but only %d are occupied", ErrInvalid, s.peeked, size)
+	}
+	if s.start >= cap || s.end >= cap {
+		return fmt.Errorf("%w: read end %d or write end %d outside of capacity %d", ErrInvalid, s.start, s.end, cap)
+	}
+	if s.empty != (size == 0) {
+		return fmt.Errorf("%w: Empty is %t at size %d", ErrInvalid, s.empty, size)
+	}
+	if s.full != (size == cap) {
+		return fmt.Errorf("%w: Full is %t at size %d of %d", ErrInvalid, s.full, size, cap)
+	}
+
+	first, second := s.first, s.second
+	for _, rg := range []Range{first, second} {
+		if rg.Start > rg.End || rg.End > cap {
+			return fmt.Errorf("%w: Inspect range %v outside of capacity %d", ErrInvalid, rg, cap)
+		}
+	}
+	if size > 0 && first.Start != s.start {
+		return fmt.Errorf("%w: Inspect range %v does not start at read end %d", ErrInvalid, first, s.start)
+	}
+	if first.Length()+second.Length() != size {
+		return fmt.Errorf("%w: Inspect ranges %v and %v do not cover size %d", ErrInvalid, first, second, size)
+	}
+	return nil
+}
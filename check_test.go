@@ -0,0 +1,52 @@
+package o
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	for _, ring := range []Ring{NewRing(0), NewRing(4), NewRing(5)} {
+		assert.NoError(t, ring.Check(), "fresh %v", ring)
+		ring.ForcePush()
+		ring.ForcePush()
+		_, _, _ = ring.Reserve(1)
+		_, _, _ = ring.PeekN(1)
+		assert.NoError(t, ring.Check(), "used %v", ring)
+	}
+
+	for _, test := range []struct {
+		name string
+		ring Ring
+	}{
+		{"overfull mask ring", Ring{&maskRing{cap: 4, read: 1, write: 6}}},
+		{"overfull basic ring", Ring{&basicRing{cap: 5, length: 6}}},
+		{"read end out of bounds", Ring{&basicRing{cap: 5, read: 5, length: 1}}},
+		{"over-reserved", Ring{&maskRing{cap: 4, write: 3, reserved: 2}}},
+		{"over-peeked", Ring{&basicRing{cap: 5, length: 1, peeked: 2}}},
+	} {
+		err := test.ring.Check()
+		assert.True(t, errors.Is(err, ErrInvalid), "%s: %v", test.name, err)
+	}
+}
+
+func TestCheckState(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name  string
+		state state
+	}{
+		{"capacity 0 with elements", state{size: 1, full: true}},
+		{"Empty disagrees", state{cap: 4, size: 1, empty: true, first: Range{0, 1}}},
+		{"Full disagrees", state{cap: 4, size: 4, first: Range{0, 4}}},
+		{"Inspect out of bounds", state{cap: 4, size: 1, first: Range{3, 5}}},
+		{"Inspect away from read end", state{cap: 4, size: 1, start: 1, end: 2, first: Range{2, 3}}},
+		{"Inspect misses elements", state{cap: 4, size: 2, end: 2, first: Range{0, 1}}},
+	} {
+		err := test.state.check()
+		assert.True(t, errors.Is(err, ErrInvalid), "%s: %v", test.name, err)
+	}
+}
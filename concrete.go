@@ -0,0 +1,588 @@
+package o
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// Accountant is the set of ring accounting operations shared by Ring,
+// MaskRing and ModRing. Code that is generic over an Accountant type
+// parameter works with all of them.
+type Accountant interface {
+	Capacity() uint
+	Size() uint
+	Empty() bool
+	Full() bool
+	Mask(uint) uint
+
+	Push() (uint, error)
+	Shift() (uint, error)
+	PushN(count uint) (first, second Range, err error)
+	ShiftN(count uint) (first, second Range, err error)
+	ForcePush() (uint, error)
+	Inspect() (first, second Range)
+	Consume() (first, second Range)
+
+	Unshift() (uint, error)
+	UnshiftN(count uint) (first, second Range, err error)
+	Pop() (uint, error)
+	PopN(count uint) (first, second Range, err error)
+
+	Reserve(count uint) (first, second Range, err error)
+	Commit(count uint)
+	Abort()
+	PeekN(count uint) (first, second Range, err error)
+	Release(count uint)
+
+	Generation() Generation
+	Validate(gen Generation, first, second Range) error
+	Check() error
+	At(i int) (uint, error)
+	Position(idx uint) (int, error)
+	Window(from, to int) (first, second Range, err error)
+	All() iter.Seq2[int, uint]
+	Backward() iter.Seq2[int, uint]
+
+	EnableStats()
+	Stats() Stats
+	ResetStats()
+}
+
+var (
+	_ Accountant = Ring{}
+	_ Accountant = MaskRing{}
+	_ Accountant = ModRing{}
+)
+
+// MaskRing is a ring accounting value type that uses bitwise masking
+// to wrap its indexes around, like the Ring returned by NewRing for
+// power-of-two capacities. It has the same methods as Ring, but since
+// its methods are not dispatched through an interface, the compiler
+// can inline the accounting into tight loops.
+//
+// Its zero value is not usable; create MaskRings with NewMaskRing. Like
+// copies of a Ring, copies of a MaskRing share their accounting state.
+type MaskRing struct {
+	r *maskRing
+}
+
+// NewMaskRing returns a MaskRing with the given capacity, which must be
+// a power of two. Otherwise, it returns ErrCapacity.
+func NewMaskRing(cap uint) (MaskRing, error) {
+	if bits.OnesCount(cap) != 1 {
+		return MaskRing{}, ErrCapacity
+	}
+	return MaskRing{r: &maskRing{cap: cap}}, nil
+}
+
+// Ring returns a Ring that shares its accounting state with r, for
+// use with code that expects a Ring (e.g. ScanFIFO).
+func (r MaskRing) Ring() Ring {
+	return Ring{r.r}
+}
+
+// Capacity returns the number of continuous indexes that can be
+// represented on the ring. See Ring.Capacity.
+func (r MaskRing) Capacity() uint {
+	return r.r.cap
+}
+
+// Size returns the number of elements in the ring buffer. See
+// Ring.Size.
+func (r MaskRing) Size() uint {
+	return r.r.size()
+}
+
+// Empty returns whether the ring has zero elements that are readable
+// on it. See Ring.Empty.
+func (r MaskRing) Empty() bool {
+	return r.r.empty()
+}
+
+// Full returns true if the ring has occupied all possible index
+// values. See Ring.Full.
+func (r MaskRing) Full() bool {
+	return r.r.full()
+}
+
+// Mask adjusts an index value to fit the ring buffer. See Ring.Mask.
+func (r MaskRing) Mask(i uint) uint {
+	return r.r.mask(i)
+}
+
+// Push accounts for a new element in the ring and returns its index.
+// See Ring.Push.
+func (r MaskRing) Push() (uint, error) {
+	start, _, err := r.r.pushN(1)
+	return start, err
+}
+
+// Shift accounts for removing the oldest element from the ring and
+// returns its index. See Ring.Shift.
+func (r MaskRing) Shift() (uint, error) {
+	start, _, err := r.r.shiftN(1)
+	return start, err
+}
+
+// PushN bulk-pushes count indexes onto the end of the ring. See
+// Ring.PushN.
+func (r MaskRing) PushN(count uint) (first, second Range, err error) {
+	return pushRanges(r.r, count)
+}
+
+// ShiftN bulk-"read"s count indexes from the start of the ring. See
+// Ring.ShiftN.
+func (r MaskRing) ShiftN(count uint) (first, second Range, err error) {
+	return shiftRanges(r.r, count)
+}
+
+// ForcePush forces a new element onto the ring, discarding the oldest
+// element if the ring is full. See Ring.ForcePush.
+func (r MaskRing) ForcePush() (uint, error) {
+	return forcePush(r.r)
+}
+
+// Inspect returns the ranges of indexes occupied in the ring. See
+// Ring.Inspect.
+func (r MaskRing) Inspect() (first, second Range) {
+	return inspect(r.r)
+}
+
+// Consume resets the ring to its empty state. See Ring.Consume.
+func (r MaskRing) Consume() (first, second Range) {
+	return consume(r.r)
+}
+
+// Unshift accounts for a new element at the read end of the ring.
+// See Ring.Unshift.
+func (r MaskRing) Unshift() (uint, error) {
+	start, _, err := r.r.unshiftN(1)
+	return start, err
+}
+
+// UnshiftN bulk-pushes count indexes onto the read end of the ring.
+// See Ring.UnshiftN.
+func (r MaskRing) UnshiftN(count uint) (first, second Range, err error) {
+	return unshiftRanges(r.r, count)
+}
+
+// Pop accounts for removing the newest element from the ring. See
+// Ring.Pop.
+func (r MaskRing) Pop() (uint, error) {
+	start, _, err := r.r.popN(1)
+	return start, err
+}
+
+// PopN bulk-removes the count newest indexes from the ring. See
+// Ring.PopN.
+func (r MaskRing) PopN(count uint) (first, second Range, err error) {
+	return popRanges(r.r, count)
+}
+
+// Reserve reserves free indexes without making them readable. See
+// Ring.Reserve.
+func (r MaskRing) Reserve(count uint) (first, second Range, err error) {
+	return reserveRanges(r.r, count)
+}
+
+// Commit makes reserved indexes readable. See Ring.Commit.
+func (r MaskRing) Commit(count uint) {
+	r.r.commit(count)
+}
+
+// Abort releases the outstanding reservation. See Ring.Abort.
+func (r MaskRing) Abort() {
+	r.r.abort()
+}
+
+// PeekN holds the oldest indexes without freeing them. See
+// Ring.PeekN.
+func (r MaskRing) PeekN(count uint) (first, second Range, err error) {
+	return peekRanges(r.r, count)
+}
+
+// Release frees peeked indexes. See Ring.Release.
+func (r MaskRing) Release(count uint) {
+	r.r.release(count)
+}
+
+// Generation identifies the ring's set of occupied indexes. See
+// Ring.Generation.
+func (r MaskRing) Generation() Generation {
+	return Generation(r.r.gen)
+}
+
+// Validate checks that ranges taken at gen are still valid. See
+// Ring.Validate.
+func (r MaskRing) Validate(gen Generation, first, second Range) error {
+	return validate(r.r, gen, first, second)
+}
+
+// At returns the index of the element at a logical position. See
+// Ring.At.
+func (r MaskRing) At(i int) (uint, error) {
+	return at(r.r, i)
+}
+
+// Position returns the logical position of an index. See
+// Ring.Position.
+func (r MaskRing) Position(idx uint) (int, error) {
+	return position(r.r, idx)
+}
+
+// Window returns the ranges covering a span of logical positions.
+// See Ring.Window.
+func (r MaskRing) Window(from, to int) (first, second Range, err error) {
+	return window(r.r, from, to)
+}
+
+// All returns an iterator over the occupied indexes in FIFO order.
+// See Ring.All.
+func (r MaskRing) All() iter.Seq2[int, uint] {
+	return all(r.r)
+}
+
+// Backward returns an iterator over the occupied indexes in LIFO
+// order. See Ring.Backward.
+func (r MaskRing) Backward() iter.Seq2[int, uint] {
+	return backward(r.r)
+}
+
+// EnableStats starts counting operations on the ring. See
+// Ring.EnableStats.
+func (r MaskRing) EnableStats() {
+	enableStats(r.r)
+}
+
+// Stats returns the operation counters of the ring. See Ring.Stats.
+func (r MaskRing) Stats() Stats {
+	return currentStats(r.r)
+}
+
+// ResetStats sets the operation counters of the ring back to zero.
+// See Ring.ResetStats.
+func (r MaskRing) ResetStats() {
+	resetStats(r.r)
+}
+
+// String returns a diagram of the ring's state. See Ring.String.
+func (r MaskRing) String() string {
+	return stateOf(r.r).String()
+}
+
+// Format prints the ring's state like Ring.Format does.
+func (r MaskRing) Format(f fmt.State, verb rune) {
+	stateOf(r.r).format(f, verb)
+}
+
+// Check verifies that the ring's accounting is consistent. See
+// Ring.Check.
+func (r MaskRing) Check() error {
+	return stateOf(r.r).check()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the same
+// format as Ring.MarshalBinary.
+func (r MaskRing) MarshalBinary() ([]byte, error) {
+	return r.Ring().MarshalBinary()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a
+// MaskRing encoded by its MarshalBinary. Like Ring.UnmarshalBinary, it
+// rejects invalid data with an error wrapping ErrInvalid, including
+// rings that were encoded with a different accounting algorithm.
+func (r *MaskRing) UnmarshalBinary(data []byte) error {
+	var ring Ring
+	if err := ring.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	backend, ok := ring.ringBackend.(*maskRing)
+	if !ok {
+		return fmt.Errorf("%w: encoded ring is not a MaskRing", ErrInvalid)
+	}
+	*r = MaskRing{r: backend}
+	return nil
+}
+
+// Resize returns a new MaskRing of capacity newCap, which must be a
+// power of two, along with the relocations that move the elements into
+// its backing buffer. Otherwise, it returns ErrCapacity. See
+// Ring.Resize.
+func (r MaskRing) Resize(newCap uint, dropOldest bool) (MaskRing, []Relocation, error) {
+	resized, err := NewMaskRing(newCap)
+	if err != nil {
+		return r, nil, err
+	}
+	plan, err := relocate(r.r, resized.r, dropOldest)
+	if err != nil {
+		return r, nil, err
+	}
+	return resized, plan, nil
+}
+
+// ModRing is a ring accounting value type that uses comparison and
+// subtraction to wrap its indexes around, like the Ring returned by
+// NewRing for other non-zero capacities. It has the same methods as
+// Ring, but since its methods are not dispatched through an
+// interface, the compiler can inline the accounting into tight loops.
+//
+// Its zero value is not usable; create ModRings with NewModRing. Like
+// copies of a Ring, copies of a ModRing share their accounting state.
+type ModRing struct {
+	r *basicRing
+}
+
+// NewModRing returns a ModRing with the given capacity, which must be
+// greater than zero. Otherwise, it returns ErrCapacity.
+func NewModRing(cap uint) (ModRing, error) {
+	if cap == 0 {
+		return ModRing{}, ErrCapacity
+	}
+	return ModRing{r: &basicRing{cap: cap}}, nil
+}
+
+// Ring returns a Ring that shares its accounting state with r, for
+// use with code that expects a Ring (e.g. ScanFIFO).
+func (r ModRing) Ring() Ring {
+	return Ring{r.r}
+}
+
+// Capacity returns the number of continuous indexes that can be
+// represented on the ring. See Ring.Capacity.
+func (r ModRing) Capacity() uint {
+	return r.r.cap
+}
+
+// Size returns the number of elements in the ring buffer. See
+// Ring.Size.
+func (r ModRing) Size() uint {
+	return r.r.size()
+}
+
+// Empty returns whether the ring has zero elements that are readable
+// on it. See Ring.Empty.
+func (r ModRing) Empty() bool {
+	return r.r.empty()
+}
+
+// Full returns true if the ring has occupied all possible index
+// values. See Ring.Full.
+func (r ModRing) Full() bool {
+	return r.r.full()
+}
+
+// Mask adjusts an index value to fit the ring buffer. See Ring.Mask.
+func (r ModRing) Mask(i uint) uint {
+	return r.r.mask(i)
+}
+
+// Push accounts for a new element in the ring and returns its index.
+// See Ring.Push.
+func (r ModRing) Push() (uint, error) {
+	start, _, err := r.r.pushN(1)
+	return start, err
+}
+
+// Shift accounts for removing the oldest element from the ring and
+// returns its index. See Ring.Shift.
+func (r ModRing) Shift() (uint, error) {
+	start, _, err := r.r.shiftN(1)
+	return start, err
+}
+
+// PushN bulk-pushes count indexes onto the end of the ring. See
+// Ring.PushN.
+func (r ModRing) PushN(count uint) (first, second Range, err error) {
+	return pushRanges(r.r, count)
+}
+
+// ShiftN bulk-"read"s count indexes from the start of the ring. See
+// Ring.ShiftN.
+func (r ModRing) ShiftN(count uint) (first, second Range, err error) {
+	return shiftRanges(r.r, count)
+}
+
+// ForcePush forces a new element onto the ring, discarding the oldest
+// element if the ring is full. See Ring.ForcePush.
+func (r ModRing) ForcePush() (uint, error) {
+	return forcePush(r.r)
+}
+
+// Inspect returns the ranges of indexes occupied in the ring. See
+// Ring.Inspect.
+func (r ModRing) Inspect() (first, second Range) {
+	return inspect(r.r)
+}
+
+// Consume resets the ring to its empty state. See Ring.Consume.
+func (r ModRing) Consume() (first, second Range) {
+	return consume(r.r)
+}
+
+// Unshift accounts for a new element at the read end of the ring.
+// See Ring.Unshift.
+func (r ModRing) Unshift() (uint, error) {
+	start, _, err := r.r.unshiftN(1)
+	return start, err
+}
+
+// UnshiftN bulk-pushes count indexes onto the read end of the ring.
+// See Ring.UnshiftN.
+func (r ModRing) UnshiftN(count uint) (first, second Range, err error) {
+	return unshiftRanges(r.r, count)
+}
+
+// Pop accounts for removing the newest element from the ring. See
+// Ring.Pop.
+func (r ModRing) Pop() (uint, error) {
+	start, _, err := r.r.popN(1)
+	return start, err
+}
+
+// PopN bulk-removes the count newest indexes from the ring. See
+// Ring.PopN.
+func (r ModRing) PopN(count uint) (first, second Range, err error) {
+	return popRanges(r.r, count)
+}
+
+// Reserve reserves free indexes without making them readable. See
+// Ring.Reserve.
+func (r ModRing) Reserve(count uint) (first, second Range, err error) {
+	return reserveRanges(r.r, count)
+}
+
+// Commit makes reserved indexes readable. See Ring.Commit.
+func (r ModRing) Commit(count uint) {
+	r.r.commit(count)
+}
+
+// Abort releases the outstanding reservation. See Ring.Abort.
+func (r ModRing) Abort() {
+	r.r.abort()
+}
+
+// PeekN holds the oldest indexes without freeing them. See
+// Ring.PeekN.
+func (r ModRing) PeekN(count uint) (first, second Range, err error) {
+	return peekRanges(r.r, count)
+}
+
+// Release frees peeked indexes. See Ring.Release.
+func (r ModRing) Release(count uint) {
+	r.r.release(count)
+}
+
+// Generation identifies the ring's set of occupied indexes. See
+// Ring.Generation.
+func (r ModRing) Generation() Generation {
+	return Generation(r.r.gen)
+}
+
+// Validate checks that ranges taken at gen are still valid. See
+// Ring.Validate.
+func (r ModRing) Validate(gen Generation, first, second Range) error {
+	return validate(r.r, gen, first, second)
+}
+
+// At returns the index of the element at a logical position. See
+// Ring.At.
+func (r ModRing) At(i int) (uint, error) {
+	return at(r.r, i)
+}
+
+// Position returns the logical position of an index. See
+// Ring.Position.
+func (r ModRing) Position(idx uint) (int, error) {
+	return position(r.r, idx)
+}
+
+// Window returns the ranges covering a span of logical positions.
+// See Ring.Window.
+func (r ModRing) Window(from, to int) (first, second Range, err error) {
+	return window(r.r, from, to)
+}
+
+// All returns an iterator over the occupied indexes in FIFO order.
+// See Ring.All.
+func (r ModRing) All() iter.Seq2[int, uint] {
+	return all(r.r)
+}
+
+// Backward returns an iterator over the occupied indexes in LIFO
+// order. See Ring.Backward.
+func (r ModRing) Backward() iter.Seq2[int, uint] {
+	return backward(r.r)
+}
+
+// EnableStats starts counting operations on the ring. See
+// Ring.EnableStats.
+func (r ModRing) EnableStats() {
+	enableStats(r.r)
+}
+
+// Stats returns the operation counters of the ring. See Ring.Stats.
+func (r ModRing) Stats() Stats {
+	return currentStats(r.r)
+}
+
+// ResetStats sets the operation counters of the ring back to zero.
+// See Ring.ResetStats.
+func (r ModRing) ResetStats() {
+	resetStats(r.r)
+}
+
+// String returns a diagram of the ring's state. See Ring.String.
+func (r ModRing) String() string {
+	return stateOf(r.r).String()
+}
+
+// Format prints the ring's state like Ring.Format does.
+func (r ModRing) Format(f fmt.State, verb rune) {
+	stateOf(r.r).format(f, verb)
+}
+
+// Check verifies that the ring's accounting is consistent. See
+// Ring.Check.
+func (r ModRing) Check() error {
+	return stateOf(r.r).check()
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the same
+// format as Ring.MarshalBinary.
+func (r ModRing) MarshalBinary() ([]byte, error) {
+	return r.Ring().MarshalBinary()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a
+// ModRing encoded by its MarshalBinary. Like Ring.UnmarshalBinary, it
+// rejects invalid data with an error wrapping ErrInvalid, including
+// rings that were encoded with a different accounting algorithm.
+func (r *ModRing) UnmarshalBinary(data []byte) error {
+	var ring Ring
+	if err := ring.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	backend, ok := ring.ringBackend.(*basicRing)
+	if !ok {
+		return fmt.Errorf("%w: encoded ring is not a ModRing", ErrInvalid)
+	}
+	*r = ModRing{r: backend}
+	return nil
+}
+
+// Resize returns a new ModRing of capacity newCap, which must be
+// greater than zero, along with the relocations that move the elements
+// into its backing buffer. Otherwise, it returns ErrCapacity. See
+// Ring.Resize.
+func (r ModRing) Resize(newCap uint, dropOldest bool) (ModRing, []Relocation, error) {
+	resized, err := NewModRing(newCap)
+	if err != nil {
+		return r, nil, err
+	}
+	plan, err := relocate(r.r, resized.r, dropOldest)
+	if err != nil {
+		return r, nil, err
+	}
+	return resized, plan, nil
+}
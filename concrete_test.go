@@ -0,0 +1,335 @@
+package o_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trace runs a sequence of operations on an Accountant and records
+// the results.
+func trace[A o.Accountant](a A, ops []int) []string {
+	var out []string
+	for _, op := range ops {
+		var res string
+		switch op {
+		case 0:
+			i, err := a.Push()
+			res = fmt.Sprint("push ", i, err)
+		case 1:
+			i, err := a.Shift()
+			res = fmt.Sprint("shift ", i, err)
+		case 2:
+			f, s, err := a.PushN(3)
+			res = fmt.Sprint("pushN ", f, s, err)
+		case 3:
+			f, s, err := a.ShiftN(2)
+			res = fmt.Sprint("shiftN ", f, s, err)
+		case 4:
+			i, err := a.ForcePush()
+			res = fmt.Sprint("forcePush ", i, err)
+		case 5:
+			i, err := a.Pop()
+			res = fmt.Sprint("pop ", i, err)
+		}
+		f, s := a.Inspect()
+		out = append(out, fmt.Sprint(res, " / ", a.Size(), a.Empty(), a.Full(), f, s))
+	}
+	return out
+}
+
+func TestPropConcreteMatchesRing(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("MaskRing and ModRing behave like Ring", prop.ForAll(
+		func(cap uint, ops []int) string {
+			expected := trace(o.NewRing(cap), ops)
+			var actual []string
+			if mask, err := o.NewMaskRing(cap); err == nil {
+				actual = trace(mask, ops)
+			} else {
+				mod, err := o.NewModRing(cap)
+				if err != nil {
+					return err.Error()
+				}
+				actual = trace(mod, ops)
+			}
+			for i := range expected {
+				if expected[i] != actual[i] {
+					return fmt.Sprintf("op %d: %q != %q", i, expected[i], actual[i])
+				}
+			}
+			return ""
+		},
+		gen.UIntRange(1, 40).WithLabel("ring size"),
+		gen.SliceOf(gen.IntRange(0, 5)).WithLabel("operations"),
+	))
+	properties.TestingRun(t)
+}
+
+func TestConcreteConstructors(t *testing.T) {
+	t.Parallel()
+	_, err := o.NewMaskRing(12)
+	assert.Equal(t, o.ErrCapacity, err)
+	_, err = o.NewMaskRing(0)
+	assert.Equal(t, o.ErrCapacity, err)
+	_, err = o.NewModRing(0)
+	assert.Equal(t, o.ErrCapacity, err)
+
+	mod, err := o.NewModRing(12)
+	require.NoError(t, err)
+	assert.Equal(t, uint(12), mod.Capacity())
+
+	// The dynamic view shares state with the concrete ring:
+	mod.PushN(3)
+	ring := mod.Ring()
+	ring.Shift()
+	assert.Equal(t, uint(2), mod.Size())
+	idx, err := mod.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), idx)
+}
+
+func TestConcreteAccountant(t *testing.T) {
+	t.Parallel()
+	var first, second o.Range
+	var gen o.Generation
+	indexes := func(seq func(func(int, uint) bool)) string {
+		var out []string
+		for pos, idx := range seq {
+			out = append(out, fmt.Sprint(pos, ":", idx))
+		}
+		return fmt.Sprint(out)
+	}
+	steps := []struct {
+		name string
+		op   func(a o.Accountant) any
+	}{
+		{"fill", func(a o.Accountant) any {
+			f1, s1, err1 := a.PushN(a.Capacity() - 1)
+			f2, s2, err2 := a.ShiftN(a.Capacity() / 2)
+			f3, s3, err3 := a.PushN(a.Capacity()/2 + 1)
+			return fmt.Sprint(f1, s1, err1, f2, s2, err2, f3, s3, err3)
+		}},
+		{"state", func(a o.Accountant) any {
+			first, second = a.Inspect()
+			gen = a.Generation()
+			return fmt.Sprint(a.Capacity(), a.Size(), a.Empty(), a.Full(), a.Mask(a.Capacity()+1), first, second, a.Check())
+		}},
+		{"string", func(a o.Accountant) any { return fmt.Sprintf("%v %+v %d %s", a, a, a, a.(fmt.Stringer).String()) }},
+		{"nothing", func(a o.Accountant) any {
+			f1, s1, err1 := a.PushN(0)
+			f2, s2, err2 := a.ShiftN(0)
+			return fmt.Sprint(f1, s1, err1, f2, s2, err2)
+		}},
+		{"at", func(a o.Accountant) any {
+			i1, err1 := a.At(0)
+			i2, err2 := a.At(-1)
+			_, err3 := a.At(int(a.Size()))
+			p1, err4 := a.Position(i1)
+			p2, err5 := a.Position(i2)
+			_, err6 := a.Position(a.Capacity())
+			return fmt.Sprint(i1, err1, i2, err2, err3, p1, err4, p2, err5, err6)
+		}},
+		{"window", func(a o.Accountant) any {
+			f1, s1, err1 := a.Window(-2, 0)
+			f2, s2, err2 := a.Window(0, int(a.Size()))
+			_, _, err3 := a.Window(1, 0)
+			_, _, err4 := a.Window(-int(a.Size())-1, 0)
+			f5, s5, err5 := a.Window(1, 1)
+			return fmt.Sprint(f1, s1, err1, f2, s2, err2, err3, err4, f5, s5, err5)
+		}},
+		{"iterate", func(a o.Accountant) any {
+			return indexes(a.All()) + indexes(a.Backward())
+		}},
+		{"validate", func(a o.Accountant) any {
+			err1 := a.Validate(gen, first, second)
+			err2 := a.Validate(gen, o.Range{Start: 0, End: a.Capacity() + 1}, o.Range{})
+			a.EnableStats()
+			a.EnableStats()
+			i, err := a.ForcePush()
+			err3 := a.Validate(gen, first, second)
+			return fmt.Sprint(err1, err2, i, err, err3, a.Stats())
+		}},
+		{"peek", func(a o.Accountant) any {
+			f, s, err := a.PeekN(1)
+			_, _, err1 := a.ShiftN(1)
+			_, err2 := a.Unshift()
+			_, _, err3 := a.UnshiftN(1)
+			return fmt.Sprint(f, s, err, err1, err2, err3, a.Check())
+		}},
+		{"force push onto peek", func(a o.Accountant) any {
+			gen := a.Generation()
+			_, err := a.ForcePush()
+			return fmt.Sprint(err, a.Generation() == gen, a.Size(), a.Check())
+		}},
+		{"release", func(a o.Accountant) any {
+			a.Release(1)
+			f, s, err := a.PeekN(0)
+			return fmt.Sprint(f, s, err, a.Size())
+		}},
+		{"reserve", func(a o.Accountant) any {
+			f, s, err := a.Reserve(1)
+			_, err1 := a.Push()
+			_, err2 := a.Pop()
+			_, _, err3 := a.PopN(1)
+			return fmt.Sprint(f, s, err, err1, err2, err3, a.Check())
+		}},
+		{"force push onto reservation", func(a o.Accountant) any {
+			gen := a.Generation()
+			_, err := a.ForcePush()
+			return fmt.Sprint(err, a.Generation() == gen, a.Size(), a.Check())
+		}},
+		{"commit", func(a o.Accountant) any {
+			a.Commit(1)
+			f, s, err := a.Reserve(1)
+			a.Abort()
+			f0, s0, err0 := a.Reserve(0)
+			return fmt.Sprint(f, s, err, f0, s0, err0, a.Size())
+		}},
+		{"deque", func(a o.Accountant) any {
+			i1, err1 := a.Pop()
+			f2, s2, err2 := a.PopN(2)
+			i3, err3 := a.Unshift()
+			f4, s4, err4 := a.UnshiftN(2)
+			f5, s5, err5 := a.UnshiftN(a.Capacity())
+			f6, s6, err6 := a.PopN(a.Capacity() + 1)
+			f7, s7, err7 := a.UnshiftN(0)
+			f8, s8, err8 := a.PopN(0)
+			return fmt.Sprint(i1, err1, f2, s2, err2, i3, err3, f4, s4, err4, f5, s5, err5, f6, s6, err6,
+				f7, s7, err7, f8, s8, err8, a.Stats())
+		}},
+		{"reset stats", func(a o.Accountant) any {
+			a.ResetStats()
+			return a.Stats()
+		}},
+		{"consume", func(a o.Accountant) any {
+			f, s := a.Consume()
+			_, err1 := a.Shift()
+			_, err2 := a.Pop()
+			_, _, err3 := a.PeekN(1)
+			_, err4 := a.Position(0)
+			_, err5 := a.At(0)
+			f6, s6, err6 := a.Window(0, 0)
+			err7 := a.Validate(a.Generation(), o.Range{}, o.Range{})
+			return fmt.Sprint(f, s, err1, err2, err3, err4, err5, f6, s6, err6, err7, a.Stats(), a.Check())
+		}},
+		{"empty string", func(a o.Accountant) any { return fmt.Sprint(a) }},
+	}
+
+	for _, cap := range []uint{1, 2, 3, 4, 5, 8, 12, 64, 65} {
+		var concrete o.Accountant
+		if mask, err := o.NewMaskRing(cap); err == nil {
+			concrete = mask
+		} else {
+			mod, err := o.NewModRing(cap)
+			require.NoError(t, err)
+			concrete = mod
+		}
+		ring := o.NewRing(cap)
+		for _, step := range steps {
+			expected, actual := runStep(ring, step.op), runStep(concrete, step.op)
+			assert.Equal(t, expected, actual, "cap %d: %s", cap, step.name)
+		}
+	}
+	mask, err := o.NewMaskRing(4)
+	require.NoError(t, err)
+	mask.PushN(3)
+	mask.Ring().Shift()
+	assert.Equal(t, uint(2), mask.Size(), "the dynamic view shares state")
+
+	copied := mask
+	copied.Push()
+	assert.Equal(t, uint(3), mask.Size(), "copies share state")
+	assert.Equal(t, o.Stats{}, mask.Stats(), "stats are disabled")
+	mask.ResetStats()
+}
+
+func TestConcreteMarshal(t *testing.T) {
+	t.Parallel()
+	mask, err := o.NewMaskRing(8)
+	require.NoError(t, err)
+	mask.PushN(6)
+	mask.ShiftN(3)
+	data, err := mask.MarshalBinary()
+	require.NoError(t, err)
+	var decoded o.MaskRing
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, fmt.Sprint(mask), fmt.Sprint(decoded))
+	var ring o.Ring
+	require.NoError(t, ring.UnmarshalBinary(data))
+	assert.Equal(t, fmt.Sprint(mask), fmt.Sprint(ring))
+
+	var mod o.ModRing
+	assert.ErrorIs(t, mod.UnmarshalBinary(data), o.ErrInvalid, "encoded with masking")
+
+	mod, err = o.NewModRing(8)
+	require.NoError(t, err)
+	mod.PushN(5)
+	mod.ShiftN(2)
+	data, err = mod.MarshalBinary()
+	require.NoError(t, err)
+	var decodedMod o.ModRing
+	require.NoError(t, decodedMod.UnmarshalBinary(data))
+	assert.Equal(t, fmt.Sprint(mod), fmt.Sprint(decodedMod))
+	assert.ErrorIs(t, decoded.UnmarshalBinary(data), o.ErrInvalid, "encoded with comparisons")
+	assert.Equal(t, fmt.Sprint(mask), fmt.Sprint(decoded), "unchanged on error")
+
+	assert.ErrorIs(t, decoded.UnmarshalBinary(data[:3]), o.ErrInvalid)
+}
+
+func TestConcreteResize(t *testing.T) {
+	t.Parallel()
+	mask, err := o.NewMaskRing(4)
+	require.NoError(t, err)
+	mask.PushN(4)
+	mask.ShiftN(2)
+	mask.PushN(1)
+	_, _, err = mask.Resize(6, false)
+	assert.Equal(t, o.ErrCapacity, err)
+	_, _, err = mask.Resize(2, false)
+	assert.Equal(t, o.ErrShrink, err)
+	resized, plan, err := mask.Resize(8, false)
+	require.NoError(t, err)
+	expected, expectedPlan, err := mask.Ring().Resize(8, false)
+	require.NoError(t, err)
+	assert.Equal(t, expectedPlan, plan)
+	assert.Equal(t, fmt.Sprint(expected), fmt.Sprint(resized))
+	assert.Equal(t, uint(8), resized.Capacity())
+
+	mod, err := o.NewModRing(6)
+	require.NoError(t, err)
+	mod.PushN(5)
+	_, _, err = mod.Resize(0, true)
+	assert.Equal(t, o.ErrCapacity, err)
+	mod.PeekN(1)
+	_, _, err = mod.Resize(8, false)
+	assert.Equal(t, o.ErrPeeked, err)
+	mod.Release(0)
+	mod.EnableStats()
+	resizedMod, plan, err := mod.Resize(4, true)
+	require.NoError(t, err)
+	expected, expectedPlan, err = mod.Ring().Resize(4, true)
+	require.NoError(t, err)
+	assert.Equal(t, expectedPlan, plan)
+	assert.Equal(t, fmt.Sprint(expected), fmt.Sprint(resizedMod))
+	assert.Equal(t, uint64(1), resizedMod.Stats().Overwritten)
+}
+
+// runStep runs op on a, and returns its result or the value it panicked
+// with.
+func runStep(a o.Accountant, op func(o.Accountant) any) (result any) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = fmt.Sprint("panic: ", r)
+		}
+	}()
+	return fmt.Sprint(op(a))
+}
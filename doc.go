@@ -11,7 +11,12 @@
 // ring at, and your code can go its merry way.
 //
 // For an example, see the ring buffer backed ReadWriter defined in
-// package ringio.
+// package ringio, or the bounded channels in package ringchan.
+//
+// If the capacity of your ring buffer is known when you write your
+// code, you can use a MaskRing or ModRing instead of a Ring: They
+// provide the same accounting without dispatching through an
+// interface, which lets the compiler inline it.
 //
 // # Behavior when full
 //
@@ -29,7 +34,13 @@
 //	o.ScanLIFO(ring) and
 //	o.ScanFIFO(ring)
 //
-// See Scanner for defails and usage examples.
+// See Scanner for defails and usage examples. The same traversals are
+// available as range-over-func iterators, over indexes or directly
+// over the elements of your backing buffer:
+//
+//	for pos, idx := range ring.All() { ... }
+//	for pos, idx := range ring.Backward() { ... }
+//	for elt := range o.Values(ring, buf) { ... }
 //
 // # Ranges across a Ring
 //
@@ -67,11 +78,17 @@
 //
 // # Thread Safety
 //
-// None of the data structures provided here are safe from data
+// Ring and the data structures built on it are not safe from data
 // races. To use them in thread-safe ring buffer implementations,
 // users must protect both the accounting operations and backing
 // buffer writes with a Mutex.
 //
+// The exceptions are SPSCRing, which can be shared without a lock
+// between exactly one producer and one consumer goroutine, and
+// MPMCRing, which can be shared between any number of them. Blocking
+// wraps a Ring in a Mutex for producers and consumers that want to
+// wait for space or data rather than poll for it.
+//
 // # Credit
 //
 // The ring buffer accounting techniques in this package and were
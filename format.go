@@ -0,0 +1,120 @@
+package o
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diagramMaxCapacity is the largest capacity for which String draws
+// a ring's cells; larger rings are described in one line.
+const diagramMaxCapacity = 32
+
+// String returns a diagram of the ring like the one in this package's
+// documentation, with occupied cells marked x, free ones marked _,
+// and the read and write ends annotated:
+//
+//	  0   1   2   3   4   5   6
+//	+---+---+---+---+---+---+---+
+//	| x | _ | _ | x | x | x | x |
+//	+---+---+---+---+---+---+---+
+//	      ^       ^ read
+//	      |
+//	      +- write
+//
+// Rings with a capacity of 0 or above 32 are described in the compact
+// one-line form that the %v verb prints instead.
+func (r Ring) String() string {
+	return stateOf(r.ringBackend).String()
+}
+
+func (s state) String() string {
+	cap := s.cap
+	if cap == 0 || cap > diagramMaxCapacity {
+		return s.compact()
+	}
+	occupied := make([]bool, cap)
+	fifo(s.first, s.second, func(_ int, idx uint) bool {
+		occupied[idx] = true
+		return true
+	})
+
+	var ruler, b strings.Builder
+	for i := uint(0); i < cap; i++ {
+		fmt.Fprintf(&ruler, "  %-2d", i)
+	}
+	b.WriteString(strings.TrimRight(ruler.String(), " ") + "\n")
+	border := strings.Repeat("+---", int(cap)) + "+\n"
+	b.WriteString(border)
+	for _, occ := range occupied {
+		if occ {
+			b.WriteString("| x ")
+		} else {
+			b.WriteString("| _ ")
+		}
+	}
+	b.WriteString("|\n")
+	b.WriteString(border)
+
+	readCol, writeCol := int(4*s.start+2), int(4*s.end+2)
+	switch {
+	case readCol == writeCol:
+		state := "empty"
+		if s.full {
+			state = "full"
+		}
+		fmt.Fprintf(&b, "%*s^ read, write (%s)", readCol, "", state)
+	case readCol < writeCol:
+		fmt.Fprintf(&b, "%*s^%*s^ write\n", readCol, "", writeCol-readCol-1, "")
+		fmt.Fprintf(&b, "%*s|\n", readCol, "")
+		fmt.Fprintf(&b, "%*s+- read", readCol, "")
+	default:
+		fmt.Fprintf(&b, "%*s^%*s^ read\n", writeCol, "", readCol-writeCol-1, "")
+		fmt.Fprintf(&b, "%*s|\n", writeCol, "")
+		fmt.Fprintf(&b, "%*s+- write", writeCol, "")
+	}
+	return b.String()
+}
+
+// compact describes the ring's accounting state in one line.
+func (s state) compact() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Ring(cap=%d size=%d", s.cap, s.size)
+	if s.cap > 0 {
+		fmt.Fprintf(&b, " read=%d write=%d", s.start, s.end)
+	}
+	if s.reserved > 0 {
+		fmt.Fprintf(&b, " reserved=%d", s.reserved)
+	}
+	if s.peeked > 0 {
+		fmt.Fprintf(&b, " peeked=%d", s.peeked)
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Format implements fmt.Formatter: The %v and %s verbs print the
+// ring's state in one line, like
+//
+//	Ring(cap=7 size=5 read=3 write=1)
+//
+// which also lists outstanding reservations and peeks, while %+v
+// prints the diagram returned by String.
+func (r Ring) Format(f fmt.State, verb rune) {
+	stateOf(r.ringBackend).format(f, verb)
+}
+
+func (s state) format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		fmt.Fprint(f, s.String())
+	case verb == 'v' || verb == 's':
+		fmt.Fprint(f, s.compact())
+	default:
+		fmt.Fprintf(f, "%%!%c(o.Ring=%s)", verb, s.compact())
+	}
+}
+
+// String returns the range in interval notation, e.g. "[3, 7)".
+func (r Range) String() string {
+	return fmt.Sprintf("[%d, %d)", r.Start, r.End)
+}
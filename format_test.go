@@ -0,0 +1,92 @@
+package o_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingString(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(3)
+	assert.Equal(t, strings.Join([]string{
+		"  0   1   2",
+		"+---+---+---+",
+		"| _ | _ | _ |",
+		"+---+---+---+",
+		"  ^ read, write (empty)",
+	}, "\n"), ring.String())
+
+	_, _, err := ring.PushN(2)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"  0   1   2",
+		"+---+---+---+",
+		"| x | x | _ |",
+		"+---+---+---+",
+		"  ^       ^ write",
+		"  |",
+		"  +- read",
+	}, "\n"), ring.String())
+
+	ring.ForcePush()
+	ring.ForcePush()
+	assert.Equal(t, strings.Join([]string{
+		"  0   1   2",
+		"+---+---+---+",
+		"| x | x | x |",
+		"+---+---+---+",
+		"      ^ read, write (full)",
+	}, "\n"), ring.String())
+	assert.Equal(t, ring.String(), fmt.Sprintf("%+v", ring))
+}
+
+func TestRingCompact(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(100)
+	_, _, err := ring.PushN(10)
+	require.NoError(t, err)
+	_, _, err = ring.Reserve(3)
+	require.NoError(t, err)
+	_, _, err = ring.PeekN(2)
+	require.NoError(t, err)
+	want := "Ring(cap=100 size=10 read=0 write=10 reserved=3 peeked=2)"
+	assert.Equal(t, want, ring.String(), "large rings are not drawn")
+	assert.Equal(t, want, fmt.Sprintf("%v", ring))
+	assert.Equal(t, want, fmt.Sprintf("%+v", ring))
+	assert.Equal(t, "%!d(o.Ring="+want+")", fmt.Sprintf("%d", ring))
+
+	assert.Equal(t, "Ring(cap=0 size=0)", o.NewRing(0).String())
+	small := o.NewRing(4)
+	assert.Equal(t, "Ring(cap=4 size=0 read=0 write=0)", fmt.Sprint(small))
+}
+
+func TestRangeString(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "[3, 7)", o.Range{Start: 3, End: 7}.String())
+}
+
+func ExampleRing_String() {
+	ring := o.NewRing(7)
+	_, _, _ = ring.PushN(7)
+	_, _, _ = ring.ShiftN(3)
+	_, _ = ring.Push()
+	fmt.Printf("%+v\n", ring)
+	fmt.Printf("%v\n", ring)
+	first, second := ring.Inspect()
+	fmt.Println(first, second)
+	// Output:
+	//   0   1   2   3   4   5   6
+	// +---+---+---+---+---+---+---+
+	// | x | _ | _ | x | x | x | x |
+	// +---+---+---+---+---+---+---+
+	//       ^       ^ read
+	//       |
+	//       +- write
+	// Ring(cap=7 size=5 read=3 write=1)
+	// [3, 7) [0, 1)
+}
@@ -1,10 +1,11 @@
 module github.com/antifuchs/o
 
-go 1.20
+go 1.23.0
 
 require (
 	github.com/leanovate/gopter v0.2.11
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/sys v0.35.0
 )
 
 require (
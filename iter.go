@@ -0,0 +1,108 @@
+package o
+
+import "iter"
+
+// All returns an iterator over the occupied indexes of the Ring in
+// FIFO (oldest to newest) order. It yields each element's logical
+// position (0 being the oldest element) along with its index.
+//
+// Ranging over All visits the same indexes in the same order as a
+// Scanner returned by ScanFIFO, and has the same caveats: The
+// iterator snapshots the occupied ranges when iteration starts, so
+// adding or removing elements during the iteration does not change
+// the indexes it yields.
+func (r Ring) All() iter.Seq2[int, uint] {
+	return all(r.ringBackend)
+}
+
+func all[B ringBackend](b B) iter.Seq2[int, uint] {
+	return func(yield func(int, uint) bool) {
+		first, second := inspect(b)
+		fifo(first, second, yield)
+	}
+}
+
+// fifo yields the indexes in first and second along with their
+// logical positions, oldest first.
+func fifo(first, second Range, yield func(int, uint) bool) {
+	pos := 0
+	for _, rg := range [2]Range{first, second} {
+		for i := rg.Start; i < rg.End; i++ {
+			if !yield(pos, i) {
+				return
+			}
+			pos++
+		}
+	}
+}
+
+// Backward returns an iterator over the occupied indexes of the Ring
+// in LIFO (newest to oldest) order. It yields each element's logical
+// position (0 being the oldest element, so positions count down) along
+// with its index.
+//
+// Ranging over Backward visits the same indexes in the same order as
+// a Scanner returned by ScanLIFO; see All for caveats.
+func (r Ring) Backward() iter.Seq2[int, uint] {
+	return backward(r.ringBackend)
+}
+
+func backward[B ringBackend](b B) iter.Seq2[int, uint] {
+	return func(yield func(int, uint) bool) {
+		first, second := inspect(b)
+		lifo(first, second, yield)
+	}
+}
+
+// lifo yields the indexes in first and second along with their
+// logical positions, newest first.
+func lifo(first, second Range, yield func(int, uint) bool) {
+	pos := int(first.Length()+second.Length()) - 1
+	for _, rg := range [2]Range{second, first} {
+		for i := rg.End; i > rg.Start; i-- {
+			if !yield(pos, i-1) {
+				return
+			}
+			pos--
+		}
+	}
+}
+
+// All returns an iterator over the indexes in the Range, in ascending
+// order. It yields each index's offset from the start of the Range
+// along with the index.
+func (r Range) All() iter.Seq2[int, uint] {
+	return func(yield func(int, uint) bool) {
+		for i := r.Start; i < r.End; i++ {
+			if !yield(int(i-r.Start), i) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the elements of buf that are
+// occupied according to ring, in FIFO (oldest to newest) order. buf
+// must be the backing buffer that ring does the accounting for.
+func Values[T any](ring Ring, buf []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, i := range ring.All() {
+			if !yield(buf[i]) {
+				return
+			}
+		}
+	}
+}
+
+// BackwardValues returns an iterator over the elements of buf that
+// are occupied according to ring, in LIFO (newest to oldest) order.
+// buf must be the backing buffer that ring does the accounting for.
+func BackwardValues[T any](ring Ring, buf []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, i := range ring.Backward() {
+			if !yield(buf[i]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package o_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropIteratorsMatchScanners(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("All and Backward match ScanFIFO and ScanLIFO", prop.ForAll(
+		func(cap, fill, read uint) string {
+			ring := o.NewRing(cap)
+			for i := uint(0); i < fill; i++ {
+				ring.ForcePush()
+			}
+			ring.ShiftN(read)
+
+			var fifo, lifo, all, backward []uint
+			for s := o.ScanFIFO(ring); s.Next(); {
+				fifo = append(fifo, s.Value())
+			}
+			for s := o.ScanLIFO(ring); s.Next(); {
+				lifo = append(lifo, s.Value())
+			}
+			for pos, idx := range ring.All() {
+				if pos != len(all) {
+					return fmt.Sprintf("All yielded position %d, expected %d", pos, len(all))
+				}
+				all = append(all, idx)
+			}
+			for pos, idx := range ring.Backward() {
+				if expected := int(ring.Size()) - 1 - len(backward); pos != expected {
+					return fmt.Sprintf("Backward yielded position %d, expected %d", pos, expected)
+				}
+				backward = append(backward, idx)
+			}
+			if !slices.Equal(fifo, all) || !slices.Equal(lifo, backward) {
+				return fmt.Sprintf("FIFO %v != All %v or LIFO %v != Backward %v", fifo, all, lifo, backward)
+			}
+			return ""
+		},
+		gen.UIntRange(0, 70).WithLabel("ring size"),
+		gen.UIntRange(0, 200).WithLabel("elements to fill in"),
+		gen.UIntRange(0, 70).WithLabel("elements to read"),
+	))
+	properties.TestingRun(t)
+}
+
+func TestIteratorsStopEarly(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(5)
+	buf := make([]int, 5)
+	for i := 0; i < 7; i++ {
+		idx, _ := ring.ForcePush()
+		buf[idx] = i
+	}
+
+	var got []int
+	for v := range o.Values(ring, buf) {
+		if v == 5 {
+			break
+		}
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{2, 3, 4}, got)
+
+	got = nil
+	for v := range o.BackwardValues(ring, buf) {
+		if v == 3 {
+			break
+		}
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{6, 5, 4}, got)
+
+	for range ring.All() {
+		break
+	}
+	for range ring.Backward() {
+		break
+	}
+}
+
+func TestRangeAll(t *testing.T) {
+	t.Parallel()
+	var offsets []int
+	var idxs []uint
+	for off, idx := range (o.Range{Start: 3, End: 6}).All() {
+		offsets = append(offsets, off)
+		idxs = append(idxs, idx)
+	}
+	assert.Equal(t, []int{0, 1, 2}, offsets)
+	assert.Equal(t, []uint{3, 4, 5}, idxs)
+
+	for range (o.Range{Start: 3, End: 6}).All() {
+		break
+	}
+	for range (o.Range{}).All() {
+		t.Error("empty range yielded an index")
+	}
+}
@@ -0,0 +1,126 @@
+package o
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+type invalidErr uint
+
+func (e invalidErr) Error() string {
+	return "invalid ring state"
+}
+
+// ErrInvalid indicates ring accounting state that is inconsistent,
+// e.g. when decoding a corrupted serialized Ring or when Check finds
+// a violated invariant.
+const ErrInvalid invalidErr = iota
+
+// The serialized form of a Ring is, in order:
+//
+//	magic   [2]byte  "or"
+//	version uint8    (currently 1)
+//	kind    uint8    (see ringKind)
+//	cap     uint64   big-endian
+//	read    uint64   big-endian, the read position, < cap
+//	write   uint64   big-endian, the write position, in [read, read+cap]
+const (
+	ringMagic0, ringMagic1 = 'o', 'r'
+	ringVersion            = 1
+	ringEncodedLen         = 4 + 3*8
+)
+
+// ringKind identifies the accounting backend of a serialized Ring.
+type ringKind uint8
+
+const (
+	zeroKind ringKind = iota
+	maskKind
+	basicKind
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. It encodes the
+// Ring's capacity, its accounting algorithm and the positions of its
+// read and write ends in a versioned, architecture-independent
+// format.
+//
+// Outstanding reservations, peeks and statistics are not encoded: A
+// decoded Ring has none.
+func (r Ring) MarshalBinary() ([]byte, error) {
+	var kind ringKind
+	switch r.ringBackend.(type) {
+	case zeroRing:
+		kind = zeroKind
+	case *maskRing:
+		kind = maskKind
+	case *basicRing:
+		kind = basicKind
+	default:
+		return nil, fmt.Errorf("%w: unknown ring backend %T", ErrInvalid, r.ringBackend)
+	}
+	data := make([]byte, ringEncodedLen)
+	data[0], data[1], data[2], data[3] = ringMagic0, ringMagic1, ringVersion, byte(kind)
+	read := r.start()
+	binary.BigEndian.PutUint64(data[4:], uint64(r.capacity()))
+	binary.BigEndian.PutUint64(data[12:], uint64(read))
+	binary.BigEndian.PutUint64(data[20:], uint64(read)+uint64(r.size()))
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a
+// Ring encoded by MarshalBinary. Data that is truncated, of an
+// unknown version, or that describes an impossible ring state is
+// rejected with an error wrapping ErrInvalid, and leaves r unchanged.
+func (r *Ring) UnmarshalBinary(data []byte) error {
+	if len(data) != ringEncodedLen {
+		return fmt.Errorf("%w: encoded ring is %d bytes long, expected %d", ErrInvalid, len(data), ringEncodedLen)
+	}
+	if data[0] != ringMagic0 || data[1] != ringMagic1 {
+		return fmt.Errorf("%w: not an encoded ring", ErrInvalid)
+	}
+	if data[2] != ringVersion {
+		return fmt.Errorf("%w: unsupported encoding version %d", ErrInvalid, data[2])
+	}
+	kind := ringKind(data[3])
+	cap64 := binary.BigEndian.Uint64(data[4:])
+	read64 := binary.BigEndian.Uint64(data[12:])
+	write64 := binary.BigEndian.Uint64(data[20:])
+	if cap64 > math.MaxUint {
+		return fmt.Errorf("%w: capacity %d does not fit this platform", ErrInvalid, cap64)
+	}
+	if write64 < read64 || write64-read64 > cap64 {
+		return fmt.Errorf("%w: write position %d is not within capacity %d of read position %d",
+			ErrInvalid, write64, cap64, read64)
+	}
+	cap, read, length := uint(cap64), uint(read64), uint(write64-read64)
+
+	switch kind {
+	case zeroKind:
+		if cap != 0 || read != 0 || length != 0 {
+			return fmt.Errorf("%w: zero-capacity ring with capacity %d", ErrInvalid, cap)
+		}
+		*r = Ring{zeroRing{}}
+	case maskKind:
+		if bits.OnesCount(cap) != 1 {
+			return fmt.Errorf("%w: power-of-two ring with capacity %d", ErrInvalid, cap)
+		}
+		if read >= cap {
+			return fmt.Errorf("%w: read position %d exceeds capacity %d", ErrInvalid, read, cap)
+		}
+		*r = Ring{&maskRing{cap: cap, read: read, write: read + length}}
+	case basicKind:
+		// ModRings of power-of-two capacities use this kind, too.
+		if cap == 0 {
+			return fmt.Errorf("%w: comparison-wrapping ring with capacity %d", ErrInvalid, cap)
+		}
+		if read >= cap {
+			return fmt.Errorf("%w: read position %d exceeds capacity %d", ErrInvalid, read, cap)
+		}
+		*r = Ring{&basicRing{cap: cap, read: read, length: length}}
+	default:
+		return fmt.Errorf("%w: unknown ring kind %d", ErrInvalid, kind)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package o_test
+
+import (
+	"encoding"
+	"errors"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ encoding.BinaryMarshaler = o.Ring{}
+var _ encoding.BinaryUnmarshaler = &o.Ring{}
+
+func TestPropMarshalRoundTrip(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("Decoded rings match the encoded ones", prop.ForAll(
+		func(cap, fill, read uint) bool {
+			ring := o.NewRing(cap)
+			for i := uint(0); i < fill; i++ {
+				ring.ForcePush()
+			}
+			ring.ShiftN(read)
+
+			data, err := ring.MarshalBinary()
+			if err != nil {
+				return false
+			}
+			var decoded o.Ring
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				return false
+			}
+			f1, s1 := ring.Inspect()
+			f2, s2 := decoded.Inspect()
+			if f1 != f2 || s1 != s2 || ring.Capacity() != decoded.Capacity() {
+				return false
+			}
+			i1, err1 := ring.Push()
+			i2, err2 := decoded.Push()
+			return i1 == i2 && err1 == err2
+		},
+		gen.UIntRange(0, 70).WithLabel("ring size"),
+		gen.UIntRange(0, 200).WithLabel("elements to fill in"),
+		gen.UIntRange(0, 70).WithLabel("elements to read"),
+	))
+	properties.TestingRun(t)
+}
+
+func TestUnmarshalRejectsInvalid(t *testing.T) {
+	t.Parallel()
+	valid := func(cap uint) []byte {
+		ring := o.NewRing(cap)
+		ring.PushN(cap / 2)
+		data, err := ring.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+	corrupt := func(data []byte, at int, val byte) []byte {
+		data = append([]byte{}, data...)
+		data[at] = val
+		return data
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", valid(8)[:20]},
+		{"trailing garbage", append(valid(8), 0)},
+		{"magic", corrupt(valid(8), 0, 'x')},
+		{"version", corrupt(valid(8), 2, 2)},
+		{"kind", corrupt(valid(8), 3, 7)},
+		{"mask kind for non-power-of-two", corrupt(valid(6), 3, 1)},
+		{"basic kind with capacity 0", corrupt(valid(0), 3, 2)},
+		{"zero kind with capacity", corrupt(valid(8), 3, 0)},
+		{"read beyond capacity", corrupt(corrupt(valid(8), 19, 9), 27, 9)},
+		{"write before read", corrupt(valid(8), 19, 5)},
+		{"size beyond capacity", corrupt(valid(8), 27, 9)},
+	}
+	for _, elt := range tests {
+		test := elt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ring := o.NewRing(3)
+			err := ring.UnmarshalBinary(test.data)
+			assert.True(t, errors.Is(err, o.ErrInvalid), "unexpected error %v", err)
+			assert.Equal(t, uint(3), ring.Capacity(), "ring is unchanged")
+		})
+	}
+}
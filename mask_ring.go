@@ -2,6 +2,9 @@ package o
 
 type maskRing struct {
 	cap, read, write uint
+	reserved, peeked uint
+	gen              uint
+	stats            *Stats
 }
 
 func (r *maskRing) mask(val uint) uint {
@@ -13,7 +16,10 @@ func (r *maskRing) start() uint {
 }
 
 func (r *maskRing) reset() {
+	r.stats.shifted(r.size())
 	r.read = r.write
+	r.peeked = 0
+	r.gen++
 }
 
 func (r *maskRing) capacity() uint {
@@ -26,23 +32,138 @@ func (r *maskRing) end() uint {
 
 func (r *maskRing) pushN(n uint) (uint, uint, error) {
 	start := r.write
+	if r.reserved > 0 {
+		i := r.mask(start)
+		return i, i, ErrReserved
+	}
 	if n > r.cap-r.size() {
 		i := r.mask(start)
+		r.stats.failedPush(ErrFull)
 		return i, i, ErrFull
 	}
 	r.write += n
+	r.gen++
+	r.stats.pushed(n, r.size())
 	return r.mask(start), r.mask(r.write), nil
 }
 
+func (r *maskRing) reserveN(n uint) (uint, uint, error) {
+	r.reserved = 0
+	if n > r.cap-r.size() {
+		i := r.mask(r.write)
+		r.stats.failedPush(ErrFull)
+		return i, i, ErrFull
+	}
+	r.reserved = n
+	return r.mask(r.write), r.mask(r.write + n), nil
+}
+
+func (r *maskRing) commit(n uint) {
+	if n > r.reserved {
+		panic("Commit called with more indexes than were reserved.")
+	}
+	r.reserved = 0
+	r.write += n
+	if n > 0 {
+		r.gen++
+		r.stats.pushed(n, r.size())
+	}
+}
+
+func (r *maskRing) abort() {
+	r.reserved = 0
+}
+
 func (r *maskRing) shiftN(n uint) (uint, uint, error) {
 	start := r.mask(r.read)
+	if r.peeked > 0 {
+		return start, start, ErrPeeked
+	}
 	if n > r.size() {
+		r.stats.failedShift(ErrEmpty)
 		return start, start, ErrEmpty
 	}
 	r.read += n
+	r.gen++
+	r.stats.shifted(n)
 	return start, r.mask(r.read), nil
 }
 
+func (r *maskRing) peekN(n uint) (uint, uint, error) {
+	start := r.mask(r.read)
+	r.peeked = 0
+	if n > r.size() {
+		r.stats.failedShift(ErrEmpty)
+		return start, start, ErrEmpty
+	}
+	r.peeked = n
+	return start, r.mask(r.read + n), nil
+}
+
+func (r *maskRing) release(n uint) {
+	if n > r.peeked {
+		panic("Release called with more indexes than were peeked.")
+	}
+	r.peeked = 0
+	r.read += n
+	if n > 0 {
+		r.gen++
+		r.stats.shifted(n)
+	}
+}
+
+func (r *maskRing) unshiftN(n uint) (uint, uint, error) {
+	if r.peeked > 0 {
+		i := r.mask(r.read)
+		return i, i, ErrPeeked
+	}
+	if n > r.cap-r.size()-r.reserved {
+		i := r.mask(r.read)
+		r.stats.failedPush(ErrFull)
+		return i, i, ErrFull
+	}
+	r.read -= n
+	r.gen++
+	r.stats.pushed(n, r.size())
+	return r.mask(r.read), r.mask(r.read + n), nil
+}
+
+func (r *maskRing) popN(n uint) (uint, uint, error) {
+	if r.reserved > 0 {
+		i := r.mask(r.write)
+		return i, i, ErrReserved
+	}
+	if n > r.size() {
+		i := r.mask(r.write)
+		r.stats.failedShift(ErrEmpty)
+		return i, i, ErrEmpty
+	}
+	if n > r.size()-r.peeked {
+		i := r.mask(r.write)
+		return i, i, ErrPeeked
+	}
+	r.write -= n
+	r.gen++
+	r.stats.shifted(n)
+	return r.mask(r.write), r.mask(r.write + n), nil
+}
+
+func (r *maskRing) outstanding() (uint, uint) {
+	return r.reserved, r.peeked
+}
+
+func (r *maskRing) generation() uint {
+	return r.gen
+}
+
+func (r *maskRing) statistics() *Stats {
+	return r.stats
+}
+
+func (r *maskRing) setStatistics(s *Stats) {
+	r.stats = s
+}
+
 func (r *maskRing) full() bool {
 	return r.size() == r.cap
 }
@@ -0,0 +1,129 @@
+package o
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// MPMCRing provides bounded queue accounting that is safe for use by
+// any number of producer and consumer goroutines at the same time,
+// without a lock. It follows Dmitry Vyukov's bounded MPMC queue
+// design, in which each index carries a sequence number recording
+// whether it is free, being written, readable or being read.
+//
+// A producer calls Push to claim an index, fills the element at that
+// index and then calls Commit to make it readable. A consumer calls
+// Shift to claim a readable index, reads the element at that index
+// and then calls Release to make it free again. Until an index is
+// committed (or released), no other party is handed that index.
+//
+// Like maskRing, MPMCRing only supports power-of-two capacities, and
+// it needs at least two indexes: With only one, a shifted index would
+// look free before it was released.
+type MPMCRing struct {
+	_       [cacheLine]byte
+	enqueue atomic.Uint64
+	_       [cacheLine - 8]byte
+	dequeue atomic.Uint64
+	_       [cacheLine - 8]byte
+
+	cap   uint
+	slots []mpmcSlot
+}
+
+type mpmcSlot struct {
+	// seq is the enqueue position at which the slot can next be
+	// pushed to; one more than that when it can be shifted from.
+	seq atomic.Uint64
+}
+
+// NewMPMCRing returns an MPMCRing with the given capacity, which must
+// be a power of two of at least 2. Otherwise, it returns ErrCapacity.
+func NewMPMCRing(cap uint) (*MPMCRing, error) {
+	if cap < 2 || bits.OnesCount(cap) != 1 {
+		return nil, ErrCapacity
+	}
+	r := &MPMCRing{cap: cap, slots: make([]mpmcSlot, cap)}
+	for i := range r.slots {
+		r.slots[i].seq.Store(uint64(i))
+	}
+	return r, nil
+}
+
+func (r *MPMCRing) mask(val uint64) uint {
+	return uint(val) & (r.cap - 1)
+}
+
+// Capacity returns the number of continuous indexes that can be
+// represented on the ring.
+func (r *MPMCRing) Capacity() uint {
+	return r.cap
+}
+
+// Push claims a free index for writing and returns it. The element
+// at that index becomes readable once the caller passes the index to
+// Commit.
+//
+// Returns ErrFull if no index is free, which includes indexes that
+// have been shifted but not released yet.
+func (r *MPMCRing) Push() (uint, error) {
+	pos := r.enqueue.Load()
+	for {
+		slot := &r.slots[r.mask(pos)]
+		dif := int64(slot.seq.Load() - pos)
+		switch {
+		case dif == 0:
+			if r.enqueue.CompareAndSwap(pos, pos+1) {
+				return r.mask(pos), nil
+			}
+			pos = r.enqueue.Load()
+		case dif < 0:
+			return r.mask(pos), ErrFull
+		default:
+			// Another producer claimed this position first.
+			pos = r.enqueue.Load()
+		}
+	}
+}
+
+// Commit marks an index returned by Push as readable.
+//
+// Committing an index that was not returned by Push, or committing
+// it twice, corrupts the ring's accounting.
+func (r *MPMCRing) Commit(idx uint) {
+	r.slots[idx].seq.Add(1)
+}
+
+// Shift claims the oldest readable index and returns it. The index
+// becomes free for writing again once the caller passes it to
+// Release.
+//
+// Returns ErrEmpty if no index is readable, which includes indexes
+// that have been pushed but not committed yet.
+func (r *MPMCRing) Shift() (uint, error) {
+	pos := r.dequeue.Load()
+	for {
+		slot := &r.slots[r.mask(pos)]
+		dif := int64(slot.seq.Load() - (pos + 1))
+		switch {
+		case dif == 0:
+			if r.dequeue.CompareAndSwap(pos, pos+1) {
+				return r.mask(pos), nil
+			}
+			pos = r.dequeue.Load()
+		case dif < 0:
+			return r.mask(pos), ErrEmpty
+		default:
+			// Another consumer claimed this position first.
+			pos = r.dequeue.Load()
+		}
+	}
+}
+
+// Release marks an index returned by Shift as free for writing.
+//
+// Releasing an index that was not returned by Shift, or releasing it
+// twice, corrupts the ring's accounting.
+func (r *MPMCRing) Release(idx uint) {
+	r.slots[idx].seq.Add(uint64(r.cap) - 1)
+}
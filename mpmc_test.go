@@ -0,0 +1,137 @@
+package o_test
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMPMCCapacity(t *testing.T) {
+	t.Parallel()
+	_, err := o.NewMPMCRing(0)
+	assert.Equal(t, o.ErrCapacity, err)
+	_, err = o.NewMPMCRing(6)
+	assert.Equal(t, o.ErrCapacity, err)
+	_, err = o.NewMPMCRing(1)
+	assert.Equal(t, o.ErrCapacity, err, "a shifted index would be pushed again before its release")
+}
+
+func TestMPMCPushShift(t *testing.T) {
+	t.Parallel()
+	r, err := o.NewMPMCRing(2)
+	require.NoError(t, err)
+
+	a, err := r.Push()
+	require.NoError(t, err)
+	b, err := r.Push()
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+	_, err = r.Push()
+	assert.Equal(t, o.ErrFull, err)
+
+	_, err = r.Shift()
+	assert.Equal(t, o.ErrEmpty, err, "uncommitted indexes are not readable")
+	r.Commit(a)
+	r.Commit(b)
+
+	got, err := r.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, a, got)
+	_, err = r.Push()
+	assert.Equal(t, o.ErrFull, err, "unreleased indexes are not writable")
+	r.Release(got)
+
+	c, err := r.Push()
+	require.NoError(t, err)
+	assert.Equal(t, a, c)
+	r.Commit(c)
+
+	got, err = r.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, b, got)
+	r.Release(got)
+	got, err = r.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, c, got)
+	r.Release(got)
+	_, err = r.Shift()
+	assert.Equal(t, o.ErrEmpty, err)
+}
+
+// TestMPMCStress hammers a small ring with several producers and
+// consumers, asserting that no index is ever held by two parties at
+// once and that every value pushed is shifted exactly once.
+func TestMPMCStress(t *testing.T) {
+	t.Parallel()
+	const (
+		producers = 4
+		consumers = 4
+		perWorker = 5000
+		total     = producers * perWorker
+	)
+	r, err := o.NewMPMCRing(8)
+	require.NoError(t, err)
+	buf := make([]int, r.Capacity())
+	owners := make([]atomic.Int32, r.Capacity())
+	seen := make([]atomic.Int32, total)
+
+	claim := func(idx uint) {
+		if !owners[idx].CompareAndSwap(0, 1) {
+			t.Errorf("index %d handed out while in use", idx)
+		}
+	}
+	unclaim := func(idx uint) {
+		owners[idx].Store(0)
+	}
+
+	var wg sync.WaitGroup
+	var consumed atomic.Int64
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; {
+				idx, err := r.Push()
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				claim(idx)
+				buf[idx] = p*perWorker + i
+				unclaim(idx)
+				r.Commit(idx)
+				i++
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for consumed.Load() < total {
+				idx, err := r.Shift()
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				claim(idx)
+				seen[buf[idx]].Add(1)
+				unclaim(idx)
+				r.Release(idx)
+				consumed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for val := range seen {
+		if n := seen[val].Load(); n != 1 {
+			t.Errorf("value %d was shifted %d times", val, n)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package o
+
+type boundsErr uint
+
+func (e boundsErr) Error() string {
+	return "position is outside the occupied part of the ring"
+}
+
+// ErrBounds indicates an attempt to address an element that is not
+// occupied in the ring.
+const ErrBounds boundsErr = iota
+
+// logical normalizes a logical position that may count from the
+// write end (if negative) into an offset from the read end of a ring
+// holding size elements, in [0, size]. If the position falls outside
+// of that, ok is false.
+func logical(pos int, size uint) (offset uint, ok bool) {
+	if pos < 0 {
+		back := uint(-pos)
+		if back > size {
+			return 0, false
+		}
+		return size - back, true
+	}
+	if uint(pos) > size {
+		return 0, false
+	}
+	return uint(pos), true
+}
+
+// At returns the index of the element at logical position i in the
+// ring: 0 is the oldest element (the next to be shifted), 1 the one
+// after it, and so on. Negative positions count from the write end
+// instead, so -1 is the newest element.
+//
+// Returns ErrBounds if there is no element at that position.
+func (r Ring) At(i int) (uint, error) {
+	return at(r.ringBackend, i)
+}
+
+func at[B ringBackend](b B, i int) (uint, error) {
+	size := b.size()
+	offset, ok := logical(i, size)
+	if !ok || offset == size {
+		return 0, ErrBounds
+	}
+	return b.mask(b.start() + offset), nil
+}
+
+// Position returns the logical position of the element at index idx,
+// that is, how many elements are older than it. It is the inverse of
+// At with a non-negative position.
+//
+// Returns ErrBounds if the index is not occupied.
+func (r Ring) Position(idx uint) (int, error) {
+	return position(r.ringBackend, idx)
+}
+
+func position[B ringBackend](b B, idx uint) (int, error) {
+	if b.empty() {
+		return 0, ErrBounds
+	}
+	start, size, capacity := b.start(), b.size(), b.capacity()
+	if idx >= capacity {
+		return 0, ErrBounds
+	}
+	offset := idx - start
+	if idx < start {
+		offset = idx + capacity - start
+	}
+	if offset >= size {
+		return 0, ErrBounds
+	}
+	return int(offset), nil
+}
+
+// Window returns ranges covering the elements at logical positions
+// from (inclusive) to to (exclusive), in the same form as Inspect.
+// Positions are interpreted as with At, and in addition to that, a to
+// position of Size (or 0, if from is negative) denotes the write end
+// of the ring. For example, Window(-5, 0) covers the five newest
+// elements.
+//
+// Returns ErrBounds if either position is outside of the ring's
+// occupied elements, or if from comes after to.
+func (r Ring) Window(from, to int) (first, second Range, err error) {
+	return window(r.ringBackend, from, to)
+}
+
+func window[B ringBackend](b B, from, to int) (first, second Range, err error) {
+	start, end, err := offsets(from, to, b.size())
+	if err != nil || start == end {
+		return
+	}
+	first, second = split(b.mask(b.start()+start), b.mask(b.start()+end), b.capacity())
+	return
+}
+
+// offsets returns the offsets from the read end that the logical
+// positions from and to map to, on a ring holding size elements.
+func offsets(from, to int, size uint) (start, end uint, err error) {
+	start, ok := logical(from, size)
+	if !ok {
+		return 0, 0, ErrBounds
+	}
+	if to == 0 && from < 0 {
+		to = int(size)
+	}
+	end, ok = logical(to, size)
+	if !ok || end < start {
+		return 0, 0, ErrBounds
+	}
+	return start, end, nil
+}
@@ -0,0 +1,118 @@
+package o_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtAndPosition(t *testing.T) {
+	t.Parallel()
+	for _, cap := range []uint{4, 5} {
+		ring := o.NewRing(cap)
+		_, err := ring.At(0)
+		assert.Equal(t, o.ErrBounds, err)
+		_, err = ring.Position(0)
+		assert.Equal(t, o.ErrBounds, err)
+
+		for i := uint(0); i < cap+2; i++ {
+			ring.ForcePush()
+		}
+		ring.Shift()
+		// Occupied: 3, 4, ..., cap-1, 0, 1
+
+		idx, err := ring.At(0)
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), idx)
+		idx, err = ring.At(-1)
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), idx)
+		idx, err = ring.At(-int(cap) + 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), idx)
+
+		_, err = ring.At(int(cap) - 1)
+		assert.Equal(t, o.ErrBounds, err)
+		_, err = ring.At(-int(cap))
+		assert.Equal(t, o.ErrBounds, err)
+
+		pos, err := ring.Position(1)
+		require.NoError(t, err)
+		assert.Equal(t, int(cap)-2, pos)
+		_, err = ring.Position(2)
+		assert.Equal(t, o.ErrBounds, err, "unoccupied")
+		_, err = ring.Position(cap)
+		assert.Equal(t, o.ErrBounds, err, "beyond capacity")
+	}
+}
+
+func TestPropAtPositionInverse(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("At and Position are inverse, and match All", prop.ForAll(
+		func(cap, fill, read uint) string {
+			ring := o.NewRing(cap)
+			for i := uint(0); i < fill; i++ {
+				ring.ForcePush()
+			}
+			ring.ShiftN(read)
+			size := int(ring.Size())
+			for pos, idx := range ring.All() {
+				at, err := ring.At(pos)
+				if err != nil || at != idx {
+					return fmt.Sprintf("At(%d) = %d, %v; expected %d", pos, at, err, idx)
+				}
+				at, err = ring.At(pos - size)
+				if err != nil || at != idx {
+					return fmt.Sprintf("At(%d) = %d, %v; expected %d", pos-size, at, err, idx)
+				}
+				back, err := ring.Position(idx)
+				if err != nil || back != pos {
+					return fmt.Sprintf("Position(%d) = %d, %v; expected %d", idx, back, err, pos)
+				}
+			}
+			return ""
+		},
+		gen.UIntRange(0, 70).WithLabel("ring size"),
+		gen.UIntRange(0, 200).WithLabel("elements to fill in"),
+		gen.UIntRange(0, 70).WithLabel("elements to read"),
+	))
+	properties.TestingRun(t)
+}
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(8)
+	for i := 0; i < 11; i++ {
+		ring.ForcePush()
+	}
+	// Occupied: 3, 4, 5, 6, 7, 0, 1, 2
+	tests := []struct {
+		from, to      int
+		first, second o.Range
+		err           error
+	}{
+		{0, 8, o.Range{3, 8}, o.Range{0, 3}, nil},
+		{1, 3, o.Range{4, 6}, o.Range{}, nil},
+		{4, 6, o.Range{7, 8}, o.Range{0, 1}, nil},
+		{-3, 0, o.Range{0, 3}, o.Range{}, nil},
+		{-4, -1, o.Range{7, 8}, o.Range{0, 2}, nil},
+		{2, 2, o.Range{}, o.Range{}, nil},
+		{3, 2, o.Range{}, o.Range{}, o.ErrBounds},
+		{0, 9, o.Range{}, o.Range{}, o.ErrBounds},
+		{-9, 0, o.Range{}, o.Range{}, o.ErrBounds},
+	}
+	for _, test := range tests {
+		first, second, err := ring.Window(test.from, test.to)
+		assert.Equal(t, test.err, err, "Window(%d, %d)", test.from, test.to)
+		assert.Equal(t, test.first, first, "Window(%d, %d)", test.from, test.to)
+		assert.Equal(t, test.second, second, "Window(%d, %d)", test.from, test.to)
+	}
+}
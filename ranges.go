@@ -33,18 +33,77 @@ func (r Range) Length() uint {
 // empty (Start & Length = 0) if there is nothing occupied on the left
 // part of the buffer.
 func (r Ring) Inspect() (first Range, second Range) {
-	if r.Empty() {
+	return inspect(r.ringBackend)
+}
+
+// inspect returns the ranges covering the occupied indexes of b.
+//
+// It and the other generic helpers that take a backend implement the
+// methods of Ring and of the concrete ring types, which instantiate
+// them with their own backend types instead of dispatching through
+// the ringBackend interface.
+func inspect[B ringBackend](b B) (first, second Range) {
+	if b.empty() {
 		return
 	}
-	first.Start = r.start()
-	end1 := r.end()
+	return split(b.start(), b.end(), b.capacity())
+}
+
+// Generation identifies the set of occupied indexes on a Ring at one
+// point in time. Taken right after Inspect, PeekN, Window and the
+// like, it lets Validate tell whether the ranges those returned are
+// still current.
+type Generation uint
+
+// Generation returns the ring's current Generation. It changes
+// whenever an element is added to or removed from the ring (at either
+// end, including by ForcePush, Consume, Commit and Release), but not
+// when indexes are reserved or peeked.
+func (r Ring) Generation() Generation {
+	return Generation(r.generation())
+}
+
+// Validate checks that the ranges first and second, taken from the
+// ring at Generation gen, are still valid, and returns ErrStale if
+// they may not be: if the ring was modified since gen, or if the
+// ranges cover indexes that are not occupied.
+//
+// Comparing generations catches modifications that leave the same
+// indexes occupied but put different elements on them, such as a
+// ForcePush onto a full ring or a ShiftN followed by an UnshiftN.
+func (r Ring) Validate(gen Generation, first, second Range) error {
+	return validate(r.ringBackend, gen, first, second)
+}
 
-	first.End = end1
-	if end1 <= first.Start {
-		second.End = end1
-		first.End = r.capacity()
+// validate checks that b is still at gen, and that first and second
+// only cover indexes that are occupied on it.
+func validate[B ringBackend](b B, gen Generation, first, second Range) error {
+	if Generation(b.generation()) != gen {
+		return ErrStale
 	}
-	return
+	occupied1, occupied2 := inspect(b)
+	capacity, full := b.capacity(), b.full()
+	for _, rg := range [2]Range{first, second} {
+		if rg.Empty() {
+			continue
+		}
+		if rg.Start > rg.End || rg.End > capacity {
+			return ErrStale
+		}
+		if full && capacity > 0 {
+			// Every index is occupied.
+			continue
+		}
+		if !occupied1.contains(rg) && !occupied2.contains(rg) {
+			return ErrStale
+		}
+	}
+	return nil
+}
+
+// contains returns whether all of other's indexes are part of r.
+func (r Range) contains(other Range) bool {
+	return r.Start <= other.Start && other.End <= r.End
 }
 
 // Consume resets the ring to its empty state, returning a set of
@@ -53,8 +112,12 @@ func (r Ring) Inspect() (first Range, second Range) {
 //
 // See also Inspect.
 func (r Ring) Consume() (first Range, second Range) {
-	defer r.reset()
-	return r.Inspect()
+	return consume(r.ringBackend)
+}
+
+func consume[B ringBackend](b B) (first, second Range) {
+	defer b.reset()
+	return inspect(b)
 }
 
 // PushN bulk-pushes count indexes onto the end of the Ring and
@@ -62,21 +125,52 @@ func (r Ring) Consume() (first Range, second Range) {
 //
 // If the Ring can not accommodate all elements before filling up,
 // PushN reserves nothing and returns ErrFull; the ranges returned in
-// this case are meaningless and have zero length.
+// this case are meaningless and have zero length. If a reservation
+// made with Reserve is outstanding, PushN returns ErrReserved.
 func (r Ring) PushN(count uint) (first, second Range, err error) {
+	return pushRanges(r.ringBackend, count)
+}
+
+func pushRanges[B ringBackend](b B, count uint) (first, second Range, err error) {
 	if count == 0 {
 		return
 	}
-	first.Start = r.end()
+	start, end, err := b.pushN(count)
+	if err != nil {
+		first = Range{Start: start, End: end}
+		return
+	}
+	first, second = split(start, end, b.capacity())
+	return
+}
 
-	first.Start, first.End, err = r.pushN(count)
+// Reserve returns ranges covering the count free indexes after the
+// write end of the Ring, without making them readable: Size, Inspect,
+// ShiftN and Scanners behave as if the reservation did not exist.
+//
+// Once the elements at the reserved indexes are filled, Commit makes
+// (some of) them readable; Abort gives them all back. While a
+// reservation is outstanding, operations that move the write end of
+// the Ring (such as PushN and PopN) return ErrReserved. Calling
+// Reserve again replaces the previous reservation.
+//
+// If the Ring can not accommodate all elements, Reserve reserves
+// nothing and returns ErrFull; the ranges returned in this case are
+// meaningless and have zero length.
+func (r Ring) Reserve(count uint) (first, second Range, err error) {
+	return reserveRanges(r.ringBackend, count)
+}
+
+func reserveRanges[B ringBackend](b B, count uint) (first, second Range, err error) {
+	start, end, err := b.reserveN(count)
 	if err != nil {
+		first = Range{Start: start, End: end}
 		return
 	}
-	if first.End <= first.Start && count > 0 {
-		second.End = first.End
-		first.End = r.capacity()
+	if count == 0 {
+		return
 	}
+	first, second = split(start, end, b.capacity())
 	return
 }
 
@@ -85,18 +179,114 @@ func (r Ring) PushN(count uint) (first, second Range, err error) {
 //
 // If the Ring holds only fewer elements as requested, ShiftN reads
 // nothing and returns ErrFull; the ranges returned in this case are
-// meaningless and have zero length.
+// meaningless and have zero length. If a peek made with PeekN is
+// outstanding, ShiftN returns ErrPeeked.
 func (r Ring) ShiftN(count uint) (first, second Range, err error) {
+	return shiftRanges(r.ringBackend, count)
+}
+
+func shiftRanges[B ringBackend](b B, count uint) (first, second Range, err error) {
 	if count == 0 {
 		return
 	}
-	first.Start, first.End, err = r.shiftN(count)
+	start, end, err := b.shiftN(count)
 	if err != nil {
+		first = Range{Start: start, End: end}
 		return
 	}
-	if first.End <= first.Start && count > 0 {
-		second.End = first.End
-		first.End = r.capacity()
+	first, second = split(start, end, b.capacity())
+	return
+}
+
+// UnshiftN bulk-pushes count indexes onto the read end of the Ring,
+// in front of all other elements, and returns ranges covering the
+// indexes that were added, in FIFO order.
+//
+// If the Ring can not accommodate all elements before filling up,
+// UnshiftN adds nothing and returns ErrFull; the ranges returned in
+// this case are meaningless and have zero length. If a peek made with
+// PeekN is outstanding, UnshiftN returns ErrPeeked.
+func (r Ring) UnshiftN(count uint) (first, second Range, err error) {
+	return unshiftRanges(r.ringBackend, count)
+}
+
+func unshiftRanges[B ringBackend](b B, count uint) (first, second Range, err error) {
+	if count == 0 {
+		return
+	}
+	start, end, err := b.unshiftN(count)
+	if err != nil {
+		first = Range{Start: start, End: end}
+		return
+	}
+	first, second = split(start, end, b.capacity())
+	return
+}
+
+// PopN bulk-removes the count newest indexes from the write end of
+// the Ring and returns ranges covering the indexes that were
+// removed, in FIFO order.
+//
+// If the Ring holds fewer elements than requested, PopN removes
+// nothing and returns ErrEmpty; the ranges returned in this case are
+// meaningless and have zero length. If a reservation made with
+// Reserve is outstanding, PopN returns ErrReserved; if it would remove
+// indexes held by an outstanding PeekN, it returns ErrPeeked.
+func (r Ring) PopN(count uint) (first, second Range, err error) {
+	return popRanges(r.ringBackend, count)
+}
+
+func popRanges[B ringBackend](b B, count uint) (first, second Range, err error) {
+	if count == 0 {
+		return
+	}
+	start, end, err := b.popN(count)
+	if err != nil {
+		first = Range{Start: start, End: end}
+		return
+	}
+	first, second = split(start, end, b.capacity())
+	return
+}
+
+// PeekN returns ranges covering the count oldest indexes on the Ring,
+// like ShiftN, but without freeing them: Writers can not reuse those
+// indexes until they are handed back with Release, so elements can be
+// decoded straight out of the backing buffer.
+//
+// While a peek is outstanding, operations that would free or move
+// the peeked indexes (such as ShiftN, UnshiftN and ForcePush on a
+// full Ring) fail with ErrPeeked. Calling PeekN again
+// replaces the previous peek; Consume ends it.
+//
+// If the Ring holds fewer elements than requested, PeekN holds
+// nothing and returns ErrEmpty; the ranges returned in this case are
+// meaningless and have zero length.
+func (r Ring) PeekN(count uint) (first, second Range, err error) {
+	return peekRanges(r.ringBackend, count)
+}
+
+func peekRanges[B ringBackend](b B, count uint) (first, second Range, err error) {
+	start, end, err := b.peekN(count)
+	if err != nil {
+		first = Range{Start: start, End: end}
+		return
+	}
+	if count == 0 {
+		return
+	}
+	first, second = split(start, end, b.capacity())
+	return
+}
+
+// split turns the masked start and end indexes of a non-empty run of
+// indexes into the two Ranges covering it on a ring of the given
+// capacity.
+func split(start, end, capacity uint) (first, second Range) {
+	first = Range{Start: start, End: end}
+	if end <= start {
+		second.End = end
+		first.End = capacity
 	}
 	return
 }
@@ -157,34 +347,65 @@ func (i *indexes) next() uint {
 // A Scanner does not update its Ring's range validity when .Next is
 // called. Adding or reading elements from the Ring while a Scanner is
 // active can mean invalidated indexes will be returned from the
-// Scanner.
+// Scanner. To detect this, switch the Scanner to checked mode with
+// Checked.
 type Scanner struct {
 	pos     *uint
 	current *indexes
 	second  *indexes
+
+	ring    Ring
+	gen     uint
+	checked bool
+	err     error
 }
 
 // ScanFIFO returns a Scanner for the given Ring that iterates over
-// the occupied indexes in FIFO (newest to oldest) direction.
+// the occupied indexes in FIFO (oldest to newest) direction.
+//
+// Ring.All is an equivalent range-over-func iterator.
 func ScanFIFO(ring Ring) *Scanner {
 	first, second := ring.Inspect()
 	return &Scanner{
 		current: first.toFIFOTraversal(),
 		second:  second.toFIFOTraversal(),
+		ring:    ring,
+		gen:     ring.generation(),
 	}
 }
 
 // ScanLIFO returns a Scanner for the given Ring that iterates over
 // the occupied indexes in LIFO (newest to oldest, think of a stack)
 // direction.
+//
+// Ring.Backward is an equivalent range-over-func iterator.
 func ScanLIFO(ring Ring) *Scanner {
 	first, second := ring.Inspect()
 	return &Scanner{
 		current: second.toLIFOTraversal(),
 		second:  first.toLIFOTraversal(),
+		ring:    ring,
+		gen:     ring.generation(),
 	}
 }
 
+// Checked switches the Scanner to checked mode and returns it. In
+// checked mode, Next verifies that the Ring was not modified since
+// the Scanner was created; if it was, Next returns false and Err
+// returns ErrStale.
+//
+// Checked mode costs an indirect call on each call to Next.
+func (s *Scanner) Checked() *Scanner {
+	s.checked = true
+	return s
+}
+
+// Err returns ErrStale if a Scanner in checked mode stopped because
+// its Ring was modified, and nil otherwise.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
 // Next advances the Scanner in the traversal direction (forward in
 // FIFO direction, backward in LIFO), returning a boolean indicating
 // whether there *is* a next position in the ring.
@@ -193,6 +414,11 @@ func ScanLIFO(ring Ring) *Scanner {
 // case, it will always return a negative result.
 func (s *Scanner) Next() bool {
 	s.pos = nil
+	if s.checked && s.ring.generation() != s.gen {
+		s.err = ErrStale
+		s.current, s.second = nil, nil
+		return false
+	}
 	ok := s.current.hasNext()
 	if ok {
 		pos := s.current.next()
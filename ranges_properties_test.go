@@ -20,6 +20,9 @@ func TestPropFIFOandLIFOMatch(t *testing.T) {
 			insert := cap + overage
 			for i := uint(0); i < insert; i++ {
 				ring.ForcePush()
+				if err := ring.Check(); err != nil {
+					return err.Error()
+				}
 			}
 			if ring.Size() != cap {
 				return "Size does not match cap"
@@ -72,15 +75,25 @@ func TestPropPushN(t *testing.T) {
 			ring := o.NewRing(cap)
 			var startIdx uint
 			for i := uint(0); i < fill; i++ {
-				startIdx = ring.Mask(ring.ForcePush() + 1)
+				idx, _ := ring.ForcePush()
+				startIdx = ring.Mask(idx + 1)
+				if err := ring.Check(); err != nil {
+					return err.Error()
+				}
 			}
 			for i := uint(0); i < read; i++ {
 				ring.Shift()
+				if err := ring.Check(); err != nil {
+					return err.Error()
+				}
 			}
 			startSize := ring.Size()
 			overflows := startSize+reserve > cap
 
 			first, second, err := ring.PushN(reserve)
+			if err := ring.Check(); err != nil {
+				return err.Error()
+			}
 			reservedAny := !first.Empty() || !second.Empty()
 			if overflows && err == nil {
 				return "expected error"
@@ -137,6 +150,9 @@ func TestPropShiftN(t *testing.T) {
 
 			for i := uint(0); i < fill; i++ {
 				ring.ForcePush()
+				if err := ring.Check(); err != nil {
+					return err.Error()
+				}
 			}
 			var startIdx uint
 			if fill > cap && cap > 0 {
@@ -147,10 +163,16 @@ func TestPropShiftN(t *testing.T) {
 				if err == nil {
 					startIdx = ring.Mask(idx + 1)
 				}
+				if err := ring.Check(); err != nil {
+					return err.Error()
+				}
 			}
 			startSize := ring.Size()
 
 			first, second, err := ring.ShiftN(read)
+			if err := ring.Check(); err != nil {
+				return err.Error()
+			}
 			overflows := read > cap || read > startSize
 			readAny := !first.Empty() || !second.Empty()
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/antifuchs/o"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLIFO(t *testing.T) {
@@ -287,6 +288,287 @@ func TestShiftN(t *testing.T) {
 	}
 }
 
+func TestUnshiftNAndPopN(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		cap           uint
+		fill          int
+		unshift       uint
+		pop           uint
+		first, second o.Range
+		err           error
+	}{
+		{
+			name:    "unshift/wrapping",
+			cap:     8,
+			fill:    2,
+			unshift: 3,
+			first:   o.Range{5, 8}, second: o.Range{0, 0},
+		},
+		{
+			name:    "unshift/full",
+			cap:     5,
+			fill:    3,
+			unshift: 3,
+			first:   o.Range{0, 0}, second: o.Range{0, 0},
+			err: o.ErrFull,
+		},
+		{
+			name:  "pop/two-ended",
+			cap:   5,
+			fill:  7,
+			pop:   4,
+			first: o.Range{3, 5}, second: o.Range{0, 2},
+		},
+		{
+			name:  "pop/empty",
+			cap:   4,
+			fill:  2,
+			pop:   3,
+			first: o.Range{2, 2}, second: o.Range{0, 0},
+			err: o.ErrEmpty,
+		},
+	}
+	for _, elt := range tests {
+		test := elt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ring := o.NewRing(test.cap)
+			for i := 0; i < test.fill; i++ {
+				ring.ForcePush()
+			}
+			var first, second o.Range
+			var err error
+			if test.unshift > 0 {
+				first, second, err = ring.UnshiftN(test.unshift)
+			} else {
+				first, second, err = ring.PopN(test.pop)
+			}
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.first, first, "first")
+			assert.Equal(t, test.second, second, "second")
+		})
+	}
+}
+
+func TestReserveCommit(t *testing.T) {
+	t.Parallel()
+	for _, cap := range []uint{4, 5} {
+		ring := o.NewRing(cap)
+		ring.ForcePush()
+		ring.ForcePush()
+		ring.Shift()
+
+		first, second, err := ring.Reserve(cap - 1)
+		require.NoError(t, err)
+		assert.Equal(t, o.Range{2, cap}, first)
+		assert.Equal(t, o.Range{0, 1}, second)
+		assert.Equal(t, uint(1), ring.Size(), "reserved indexes are not readable")
+		readable, _ := ring.Inspect()
+		assert.Equal(t, o.Range{1, 2}, readable)
+
+		_, err = ring.Push()
+		assert.Equal(t, o.ErrReserved, err)
+		_, err = ring.Pop()
+		assert.Equal(t, o.ErrReserved, err)
+		_, err = ring.Unshift()
+		assert.Equal(t, o.ErrFull, err, "reserved indexes are not free")
+		gen := ring.Generation()
+		_, err = ring.ForcePush()
+		assert.Equal(t, o.ErrReserved, err)
+		assert.Equal(t, gen, ring.Generation(), "nothing was discarded")
+
+		ring.Commit(2)
+		assert.Equal(t, uint(3), ring.Size())
+		assert.Panics(t, func() { ring.Commit(1) }, "reservation has ended")
+
+		idx, err := ring.Push()
+		require.NoError(t, err)
+		assert.Equal(t, ring.Mask(4), idx, "uncommitted rest of the reservation is free")
+	}
+}
+
+func TestReserveAbort(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(4)
+	_, _, err := ring.Reserve(5)
+	assert.Equal(t, o.ErrFull, err)
+
+	first, second, err := ring.Reserve(4)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{0, 4}, first)
+	assert.True(t, second.Empty())
+	ring.Abort()
+	assert.True(t, ring.Empty())
+
+	idx, err := ring.Push()
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), idx)
+
+	_, _, err = ring.Reserve(0)
+	require.NoError(t, err)
+	_, err = ring.Push()
+	assert.NoError(t, err, "empty reservations do not block pushes")
+}
+
+func TestPeekRelease(t *testing.T) {
+	t.Parallel()
+	for _, cap := range []uint{4, 5} {
+		ring := o.NewRing(cap)
+		for i := uint(0); i < cap+2; i++ {
+			ring.ForcePush()
+		}
+
+		_, _, err := ring.PeekN(cap + 1)
+		assert.Equal(t, o.ErrEmpty, err)
+		first, second, err := ring.PeekN(cap - 1)
+		require.NoError(t, err)
+		assert.Equal(t, o.Range{2, cap}, first)
+		assert.Equal(t, o.Range{0, 1}, second)
+		assert.Equal(t, cap, ring.Size(), "peeked indexes stay occupied")
+
+		_, err = ring.Shift()
+		assert.Equal(t, o.ErrPeeked, err)
+		_, err = ring.Unshift()
+		assert.Equal(t, o.ErrPeeked, err)
+		_, _, err = ring.PopN(2)
+		assert.Equal(t, o.ErrPeeked, err)
+		gen := ring.Generation()
+		_, err = ring.ForcePush()
+		assert.Equal(t, o.ErrPeeked, err)
+		assert.Equal(t, gen, ring.Generation(), "nothing was discarded")
+		assert.Equal(t, cap, ring.Size())
+
+		idx, err := ring.Pop()
+		require.NoError(t, err, "unpeeked newest element can be popped")
+		assert.Equal(t, uint(1), idx)
+
+		ring.Release(2)
+		assert.Equal(t, cap-3, ring.Size())
+		assert.Panics(t, func() { ring.Release(1) }, "peek has ended")
+		idx, err = ring.Shift()
+		require.NoError(t, err)
+		assert.Equal(t, ring.Mask(4), idx)
+	}
+}
+
+func TestCheckedScanner(t *testing.T) {
+	t.Parallel()
+	mutations := map[string]func(o.Ring){
+		"push":    func(r o.Ring) { r.Push() },
+		"shift":   func(r o.Ring) { r.Shift() },
+		"unshift": func(r o.Ring) { r.Unshift() },
+		"pop":     func(r o.Ring) { r.Pop() },
+		"consume": func(r o.Ring) { r.Consume() },
+		"commit": func(r o.Ring) {
+			r.Reserve(1)
+			r.Commit(1)
+		},
+		"release": func(r o.Ring) {
+			r.PeekN(1)
+			r.Release(1)
+		},
+	}
+	for name, mutate := range mutations {
+		for _, cap := range []uint{4, 5} {
+			ring := o.NewRing(cap)
+			ring.PushN(2)
+
+			unchecked := o.ScanFIFO(ring)
+			checked := o.ScanLIFO(ring).Checked()
+			require.True(t, checked.Next())
+			require.True(t, unchecked.Next())
+			mutate(ring)
+
+			assert.False(t, checked.Next(), "%s/%d", name, cap)
+			assert.Equal(t, o.ErrStale, checked.Err(), "%s/%d", name, cap)
+			assert.False(t, checked.Next(), "%s/%d", name, cap)
+
+			assert.True(t, unchecked.Next(), "%s/%d: unchecked scanners don't notice", name, cap)
+			assert.NoError(t, unchecked.Err())
+		}
+	}
+
+	ring := o.NewRing(3)
+	ring.PushN(2)
+	s := o.ScanFIFO(ring).Checked()
+	ring.Reserve(1)
+	ring.Abort()
+	ring.PeekN(1)
+	ring.Release(0)
+	n := 0
+	for s.Next() {
+		n++
+	}
+	assert.Equal(t, 2, n, "reservations and peeks don't invalidate")
+	assert.NoError(t, s.Err())
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(5)
+	ring.PushN(7 - 5)
+	ring.ShiftN(2)
+	ring.PushN(4)
+	first, second := ring.Inspect()
+	gen := ring.Generation()
+	require.Equal(t, o.Range{2, 5}, first)
+	require.Equal(t, o.Range{0, 1}, second)
+	assert.NoError(t, ring.Validate(gen, first, second))
+	assert.NoError(t, ring.Validate(gen, o.Range{3, 4}, o.Range{}))
+	assert.Equal(t, o.ErrStale, ring.Validate(gen, o.Range{1, 2}, o.Range{}), "unoccupied")
+	assert.Equal(t, o.ErrStale, ring.Validate(gen, o.Range{4, 6}, o.Range{}), "out of bounds")
+
+	ring.Reserve(1)
+	ring.Abort()
+	ring.PeekN(1)
+	ring.Release(0)
+	assert.NoError(t, ring.Validate(gen, first, second), "reservations and peeks don't invalidate")
+
+	ring.Push()
+	assert.Equal(t, o.ErrStale, ring.Validate(gen, first, second))
+	gen = ring.Generation()
+	assert.NoError(t, ring.Validate(gen, o.Range{0, 5}, o.Range{}), "full ring occupies everything")
+
+	ring.ShiftN(2)
+	assert.Equal(t, o.ErrStale, ring.Validate(gen, o.Range{4, 5}, o.Range{0, 2}))
+	assert.NoError(t, ring.Validate(ring.Generation(), o.Range{4, 5}, o.Range{0, 2}))
+
+	gen = ring.Generation()
+	ring.Consume()
+	assert.Equal(t, o.ErrStale, ring.Validate(gen, o.Range{4, 5}, o.Range{}))
+	assert.Equal(t, o.ErrStale, ring.Validate(ring.Generation(), o.Range{4, 5}, o.Range{}))
+	assert.NoError(t, ring.Validate(ring.Generation(), o.Range{}, o.Range{}))
+}
+
+func TestValidateSameIndexes(t *testing.T) {
+	t.Parallel()
+	for _, cap := range []uint{4, 5} {
+		ring := o.NewRing(cap)
+		ring.PushN(cap)
+		first, second := ring.Inspect()
+		gen := ring.Generation()
+		ring.ForcePush()
+		after1, after2 := ring.Inspect()
+		require.Equal(t, first.Length()+second.Length(), after1.Length()+after2.Length())
+		assert.Equal(t, o.ErrStale, ring.Validate(gen, first, second),
+			"cap %d: ForcePush on a full ring overwrote the oldest element", cap)
+
+		ring = o.NewRing(cap)
+		ring.PushN(3)
+		first, second = ring.Inspect()
+		gen = ring.Generation()
+		ring.ShiftN(2)
+		ring.UnshiftN(2)
+		after1, after2 = ring.Inspect()
+		require.Equal(t, first, after1)
+		require.Equal(t, second, after2)
+		assert.Equal(t, o.ErrStale, ring.Validate(gen, first, second),
+			"cap %d: shifted elements were replaced by unshifted ones", cap)
+	}
+}
+
 func TestBadTraversalPanics(t *testing.T) {
 	t.Parallel()
 	r := o.NewRing(20)
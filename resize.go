@@ -0,0 +1,96 @@
+package o
+
+type shrinkErr uint
+
+func (e shrinkErr) Error() string {
+	return "ring contents do not fit into the new capacity"
+}
+
+// ErrShrink indicates an attempt to resize a ring to a capacity
+// smaller than the number of elements on it.
+const ErrShrink shrinkErr = iota
+
+// Relocation describes one step in moving the elements of a ring
+// buffer into a resized ring buffer: The elements in the From range
+// of the old backing buffer belong into the To range of the new one.
+// From and To always have the same length.
+type Relocation struct {
+	From Range // Indexes in the old backing buffer.
+	To   Range // Indexes in the new backing buffer.
+}
+
+// Resize returns a new Ring of capacity newCap that holds as many
+// elements as r does, along with the relocations that move those
+// elements from r's backing buffer into a new backing buffer for the
+// returned Ring, oldest first. As with NewRing, the returned Ring uses
+// bitwise index manipulation if newCap is a power of 2.
+//
+// If r holds more elements than fit into newCap, Resize returns
+// ErrShrink, unless dropOldest is set: In that case, only the newest
+// newCap elements are carried over.
+//
+// If statistics are enabled on r, the returned Ring continues
+// counting from r's counters; dropped elements count as overwritten.
+//
+// Resize does not modify r, which should be discarded along with its
+// backing buffer once the relocations have been performed. Resizing a
+// Ring that has an outstanding reservation or peek fails with
+// ErrReserved or ErrPeeked.
+func (r Ring) Resize(newCap uint, dropOldest bool) (Ring, []Relocation, error) {
+	resized := NewRing(newCap)
+	plan, err := relocate(r.ringBackend, resized.ringBackend, dropOldest)
+	if err != nil {
+		return r, nil, err
+	}
+	return resized, plan, nil
+}
+
+// relocate fills the empty backend resized with as many elements as r
+// holds (or as fit, if dropOldest is set), carries over r's
+// statistics, and returns the relocations that move the elements.
+func relocate[B ringBackend](r, resized B, dropOldest bool) ([]Relocation, error) {
+	reserved, peeked := r.outstanding()
+	if reserved > 0 {
+		return nil, ErrReserved
+	}
+	if peeked > 0 {
+		return nil, ErrPeeked
+	}
+	size, newCap := r.size(), resized.capacity()
+	if size > newCap && !dropOldest {
+		return nil, ErrShrink
+	}
+
+	first, second := inspect(r)
+	if size > newCap {
+		skip := size - newCap
+		if skip >= first.Length() {
+			skip -= first.Length()
+			first, second = Range{Start: second.Start + skip, End: second.End}, Range{}
+		} else {
+			first.Start += skip
+		}
+	}
+
+	var plan []Relocation
+	var at uint
+	for _, from := range []Range{first, second} {
+		if from.Empty() {
+			continue
+		}
+		plan = append(plan, Relocation{
+			From: from,
+			To:   Range{Start: at, End: at + from.Length()},
+		})
+		at += from.Length()
+	}
+	if at > 0 {
+		_, _, _ = resized.pushN(at)
+	}
+	if stats := r.statistics(); stats != nil {
+		carried := *stats
+		carried.Overwritten += uint64(size - at)
+		resized.setStatistics(&carried)
+	}
+	return plan, nil
+}
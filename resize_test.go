@@ -0,0 +1,91 @@
+package o_test
+
+import (
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// relocate performs a relocation plan, returning the new backing
+// buffer.
+func relocate(old []int, newCap uint, plan []o.Relocation) []int {
+	buf := make([]int, newCap)
+	for _, step := range plan {
+		copy(buf[step.To.Start:step.To.End], old[step.From.Start:step.From.End])
+	}
+	return buf
+}
+
+func contents(ring o.Ring, buf []int) []int {
+	vals := []int{}
+	for s := o.ScanFIFO(ring); s.Next(); {
+		vals = append(vals, buf[s.Value()])
+	}
+	return vals
+}
+
+func TestResize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		cap        uint
+		fill       int
+		newCap     uint
+		dropOldest bool
+		expected   []int
+		err        error
+	}{
+		{"grow/basic-to-mask", 5, 7, 8, false, []int{2, 3, 4, 5, 6}, nil},
+		{"grow/mask-to-basic", 4, 6, 9, false, []int{2, 3, 4, 5}, nil},
+		{"shrink/fits", 8, 10, 5, false, nil, o.ErrShrink},
+		{"shrink/drop-into-second", 5, 7, 3, true, []int{4, 5, 6}, nil},
+		{"shrink/drop-from-first", 5, 7, 4, true, []int{3, 4, 5, 6}, nil},
+		{"shrink/partial", 8, 3, 4, false, []int{0, 1, 2}, nil},
+		{"empty", 4, 0, 3, false, []int{}, nil},
+		{"to-zero", 4, 2, 0, true, []int{}, nil},
+	}
+	for _, elt := range tests {
+		test := elt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ring := o.NewRing(test.cap)
+			buf := make([]int, test.cap)
+			for i := 0; i < test.fill; i++ {
+				idx, _ := ring.ForcePush()
+				buf[idx] = i
+			}
+
+			resized, plan, err := ring.Resize(test.newCap, test.dropOldest)
+			assert.Equal(t, test.err, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, test.newCap, resized.Capacity())
+			assert.Equal(t, uint(len(test.expected)), resized.Size())
+			for _, step := range plan {
+				assert.Equal(t, step.From.Length(), step.To.Length())
+			}
+			newBuf := relocate(buf, test.newCap, plan)
+			assert.Equal(t, test.expected, contents(resized, newBuf))
+		})
+	}
+}
+
+func TestResizeOutstanding(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(4)
+	ring.ForcePush()
+
+	_, _, err := ring.Reserve(1)
+	require.NoError(t, err)
+	_, _, err = ring.Resize(8, false)
+	assert.Equal(t, o.ErrReserved, err)
+	ring.Abort()
+
+	_, _, err = ring.PeekN(1)
+	require.NoError(t, err)
+	_, _, err = ring.Resize(8, false)
+	assert.Equal(t, o.ErrPeeked, err)
+}
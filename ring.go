@@ -14,12 +14,54 @@ func (e emptyErr) Error() string {
 	return "reading from an empty ring"
 }
 
+type capacityErr uint
+
+func (e capacityErr) Error() string {
+	return "unsupported ring capacity"
+}
+
+type reservedErr uint
+
+func (e reservedErr) Error() string {
+	return "ring has an outstanding reservation"
+}
+
+type peekedErr uint
+
+func (e peekedErr) Error() string {
+	return "ring has an outstanding peek"
+}
+
+type staleErr uint
+
+func (e staleErr) Error() string {
+	return "ring was modified after indexes were taken from it"
+}
+
 // ErrEmpty indicates a removal operation on an empty ring.
 const ErrEmpty emptyErr = iota
 
 // ErrFull indicates an addition operation on a full ring.
 const ErrFull fullErr = iota
 
+// ErrCapacity indicates an attempt to create a ring with a capacity
+// that its accounting algorithm does not support, e.g. a ring that
+// only supports power-of-two capacities with a different capacity.
+const ErrCapacity capacityErr = iota
+
+// ErrReserved indicates an operation that would move the write end of
+// a ring while a reservation made with Reserve is outstanding.
+const ErrReserved reservedErr = iota
+
+// ErrPeeked indicates an operation that would free or move indexes
+// held by an outstanding PeekN.
+const ErrPeeked peekedErr = iota
+
+// ErrStale indicates that indexes taken from a ring (e.g. by a
+// Scanner) may no longer be valid, because the ring was modified in
+// the meantime.
+const ErrStale staleErr = iota
+
 // Ring provides accounting functions for ring buffers.
 type Ring struct {
 	ringBackend
@@ -46,7 +88,7 @@ type ringBackend interface {
 	capacity() uint
 
 	// reset adjusts the difference between the read and write
-	// points of the ring back to 0.
+	// points of the ring back to 0, ending any outstanding peek.
 	reset()
 
 	// pushN accounts for n new elements in the ring and returns
@@ -55,10 +97,63 @@ type ringBackend interface {
 	// only ErrNotFound.
 	pushN(n uint) (start uint, end uint, err error)
 
+	// reserveN marks the n free indexes after the write end of
+	// the ring as reserved, without making them readable, and
+	// returns the first and last (masked) index. It replaces any
+	// previous reservation. If not enough indexes are free,
+	// reserves nothing and returns ErrFull.
+	reserveN(n uint) (start uint, end uint, err error)
+
+	// commit makes the first n reserved indexes readable and
+	// ends the reservation. It panics if fewer than n indexes
+	// are reserved.
+	commit(n uint)
+
+	// abort ends the reservation without making any of its
+	// indexes readable.
+	abort()
+
+	// outstanding returns the number of reserved and peeked
+	// indexes.
+	outstanding() (reserved, peeked uint)
+
+	// generation returns a counter that changes whenever the set
+	// of occupied indexes on the ring changes.
+	generation() uint
+
 	// shiftN "reads" n continuous indexes from the ring and
 	// returns the first and last (masked) index. If n is larger
 	// than the ring's Size, returns zeroes and ErrEmpty.
 	shiftN(n uint) (start uint, end uint, err error)
+
+	// peekN marks the n oldest indexes on the ring as peeked,
+	// without freeing them, and returns the first and last
+	// (masked) index. It replaces any previous peek. If n is
+	// larger than the ring's Size, peeks nothing and returns
+	// ErrEmpty.
+	peekN(n uint) (start uint, end uint, err error)
+
+	// release frees the first n peeked indexes and ends the
+	// peek. It panics if fewer than n indexes are peeked.
+	release(n uint)
+
+	// unshiftN accounts for n new elements in front of the read
+	// end of the ring and returns the first and last (masked)
+	// index of the new elements. If not all elements can be
+	// inserted, does not insert them and returns ErrFull.
+	unshiftN(n uint) (start uint, end uint, err error)
+
+	// popN removes n continuous indexes from the write end of the
+	// ring and returns the first and last (masked) index. If n is
+	// larger than the ring's Size, returns ErrEmpty.
+	popN(n uint) (start uint, end uint, err error)
+
+	// statistics returns the ring's operation counters, or nil if
+	// statistics are disabled.
+	statistics() *Stats
+
+	// setStatistics makes the ring count its operations in s.
+	setStatistics(s *Stats)
 }
 
 // Capacity returns the number of continuous indexes that can be
@@ -82,12 +177,30 @@ func (r Ring) Empty() bool {
 // circumstances, but can have disastrous consequences for code that
 // expects to read consistent data. It is generally safer to use .Push
 // and handle ErrFull explicitly.
-func (r Ring) ForcePush() uint {
-	if r.full() {
-		_, _ = r.Shift()
+//
+// ForcePush returns ErrReserved if a reservation made with Reserve is
+// outstanding, and ErrPeeked if discarding the oldest element would
+// free an index held by an outstanding PeekN; in both cases, the ring
+// is left unchanged. On a ring of capacity 0, it returns ErrFull.
+func (r Ring) ForcePush() (uint, error) {
+	return forcePush(r.ringBackend)
+}
+
+func forcePush[B ringBackend](b B) (uint, error) {
+	if b.full() {
+		// A full ring has no room for a reservation, so shifting
+		// can not discard an element for a push that fails with
+		// ErrReserved.
+		_, _, err := b.shiftN(1)
+		if err == ErrPeeked {
+			return 0, err
+		}
+		if err == nil {
+			b.statistics().overwrote()
+		}
 	}
-	i, _ := r.Push()
-	return i
+	i, _, err := b.pushN(1)
+	return i, err
 }
 
 // Full returns true if the Ring has occupied all possible index
@@ -101,8 +214,9 @@ func (r Ring) Full() bool {
 // value.
 //
 // This method is probably most useful in tests, or when doing
-// low-level things not supported by o.Ring yet. If you find yourself
-// relying on this in code, please file a bug.
+// low-level things not supported by o.Ring yet. To address elements
+// by their position in the ring, use At, Position and Window instead.
+// If you find yourself relying on this in code, please file a bug.
 func (r Ring) Mask(i uint) uint {
 	return r.mask(i)
 }
@@ -116,9 +230,9 @@ func (r Ring) Mask(i uint) uint {
 // If cap is 0, returns a Ring that does not perform any operations
 // and only returns errors.
 //
-// Otherwise, the returned data structure uses general modulo division
-// for its integer adjustments, and is a lot slower than the
-// power-of-2 variant.
+// Otherwise, the returned data structure wraps its indexes around
+// with a comparison and subtraction, which is only slightly slower
+// than the power-of-2 variant.
 func NewRing(cap uint) Ring {
 	if cap == 0 {
 		return Ring{zeroRing{}}
@@ -150,7 +264,8 @@ func NewRingForSlice(i Slice) Ring {
 // Push lets a writer account for a new element in the ring,
 // and returns that element's index.
 //
-// Returns ErrFull if the ring is filled to capacity.
+// Returns ErrFull if the ring is filled to capacity, and ErrReserved
+// if a reservation made with Reserve is outstanding.
 func (r Ring) Push() (uint, error) {
 	start, _, err := r.pushN(1)
 	return start, err
@@ -159,12 +274,66 @@ func (r Ring) Push() (uint, error) {
 // Shift lets a reader account for removing an element from
 // the ring for reading, returning that element's index.
 //
-// Returns ErrEmpty if the ring has no elements to read.
+// Returns ErrEmpty if the ring has no elements to read, and
+// ErrPeeked if a peek made with PeekN is outstanding.
 func (r Ring) Shift() (uint, error) {
 	start, _, err := r.shiftN(1)
 	return start, err
 }
 
+// Unshift lets a writer account for a new element at the read end
+// of the ring, in front of all other elements, and returns that
+// element's index. The element is the next one to be shifted.
+//
+// Returns ErrFull if the ring is filled to capacity, and ErrPeeked if
+// a peek made with PeekN is outstanding.
+func (r Ring) Unshift() (uint, error) {
+	start, _, err := r.unshiftN(1)
+	return start, err
+}
+
+// Pop lets a reader account for removing the newest element from the
+// write end of the ring, returning that element's index.
+//
+// Returns ErrEmpty if the ring has no elements to read, ErrReserved
+// if a reservation made with Reserve is outstanding, and ErrPeeked if
+// the newest element is held by an outstanding PeekN.
+func (r Ring) Pop() (uint, error) {
+	start, _, err := r.popN(1)
+	return start, err
+}
+
+// Commit makes the first count indexes of the outstanding reservation
+// readable, and releases the rest of the reservation. Commit(0) is
+// equivalent to Abort.
+//
+// Commit panics if count exceeds the number of reserved indexes.
+//
+// See Reserve.
+func (r Ring) Commit(count uint) {
+	r.commit(count)
+}
+
+// Abort releases the outstanding reservation without making any of
+// its indexes readable. Calling Abort when no reservation is
+// outstanding does nothing.
+//
+// See Reserve.
+func (r Ring) Abort() {
+	r.abort()
+}
+
+// Release frees the first count indexes of the outstanding peek, as
+// if they had been shifted, and ends the peek. Release(0) ends the
+// peek without freeing anything.
+//
+// Release panics if count exceeds the number of peeked indexes.
+//
+// See PeekN.
+func (r Ring) Release(count uint) {
+	r.release(count)
+}
+
 // Size returns the number of elements in the ring buffer.
 func (r Ring) Size() uint {
 	return r.size()
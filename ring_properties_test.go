@@ -2,6 +2,8 @@ package o_test
 
 import (
 	"fmt"
+	"reflect"
+	"runtime"
 	"testing"
 
 	"github.com/antifuchs/o"
@@ -19,7 +21,13 @@ func TestPropShiftPushes(t *testing.T) {
 			ring := o.NewRing(ringSize)
 			for i := uint(0); i < entries; i++ {
 				pushed, _ := ring.Push()
+				if ring.Check() != nil {
+					return false
+				}
 				shifted, _ := ring.Shift()
+				if ring.Check() != nil {
+					return false
+				}
 
 				if pushed != shifted {
 					return false
@@ -30,6 +38,78 @@ func TestPropShiftPushes(t *testing.T) {
 		gen.UInt().WithLabel("ring size"),
 		gen.UIntRange(1, 257*90).WithLabel("number of entries made"),
 	))
+	properties.Property("Read own writes concurrently", prop.ForAll(
+		func(power uint, chunk uint, total int) string {
+			r, err := o.NewSPSCRing(1 << power)
+			if err != nil {
+				return err.Error()
+			}
+			buf := make([]int, r.Capacity())
+
+			// The producer stops early if the consumer gives up,
+			// and the consumer waits for it before returning.
+			stop, done := make(chan struct{}), make(chan struct{})
+			defer func() {
+				close(stop)
+				<-done
+			}()
+			go func() {
+				defer close(done)
+				for i := 0; i < total; {
+					n := chunk
+					if n > r.Capacity() {
+						n = r.Capacity()
+					}
+					if rest := uint(total - i); n > rest {
+						n = rest
+					}
+					first, second, err := r.Reserve(n)
+					if err != nil {
+						// Wait for the consumer to catch up:
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						runtime.Gosched()
+						continue
+					}
+					for _, rg := range []o.Range{first, second} {
+						for idx := rg.Start; idx < rg.End; idx++ {
+							buf[idx] = i
+							i++
+						}
+					}
+					r.Publish(n)
+				}
+			}()
+
+			for next := 0; next < total; {
+				first, second, err := r.Peek(1)
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				if readable := r.Size(); readable > 1 {
+					first, second, _ = r.Peek(readable)
+				}
+				n := first.Length() + second.Length()
+				for _, rg := range []o.Range{first, second} {
+					for idx := rg.Start; idx < rg.End; idx++ {
+						if buf[idx] != next {
+							return fmt.Sprintf("read %d at %d, expected %d", buf[idx], idx, next)
+						}
+						next++
+					}
+				}
+				r.Release(n)
+			}
+			return ""
+		},
+		gen.UIntRange(0, 6).WithLabel("log2 of ring size"),
+		gen.UIntRange(1, 64).WithLabel("producer chunk size"),
+		gen.IntRange(0, 5000).WithLabel("number of elements"),
+	))
 	properties.TestingRun(t)
 }
 
@@ -43,6 +123,9 @@ func TestPropBounds(t *testing.T) {
 			ring := o.NewRing(ringSize)
 
 			wFirst, wSecond, err := ring.PushN(entries)
+			if err := ring.Check(); err != nil {
+				return err.Error()
+			}
 			if entries > ringSize {
 				if err == nil {
 					return "should have errored"
@@ -66,6 +149,9 @@ func TestPropBounds(t *testing.T) {
 			}
 
 			first, second := ring.Consume()
+			if err := ring.Check(); err != nil {
+				return err.Error()
+			}
 			if (wFirst.Start != first.Start && first.End != wFirst.End+1) ||
 				(!second.Empty() && wSecond.Start != second.Start && second.End != wSecond.End+1) {
 				return fmt.Sprintf("Expected same ranges, but\n%#v %#v\n%#v %#v",
@@ -79,3 +165,135 @@ func TestPropBounds(t *testing.T) {
 	))
 	properties.TestingRun(t)
 }
+
+// dequeModel is a naive double-ended queue that mirrors the
+// operations done on a Ring (and a buffer that the Ring accounts
+// for), to check the Ring's results against.
+type dequeModel struct {
+	ring  o.Ring
+	buf   []int
+	elts  []int
+	label int
+}
+
+func (m *dequeModel) write(first, second o.Range) []int {
+	written := []int{}
+	for _, r := range []o.Range{first, second} {
+		for i := r.Start; i < r.End; i++ {
+			m.label++
+			m.buf[i] = m.label
+			written = append(written, m.label)
+		}
+	}
+	return written
+}
+
+func (m *dequeModel) read(first, second o.Range) []int {
+	read := []int{}
+	for _, r := range []o.Range{first, second} {
+		for i := r.Start; i < r.End; i++ {
+			read = append(read, m.buf[i])
+		}
+	}
+	return read
+}
+
+// apply performs op on the ring and the model and returns a
+// description of any mismatch.
+func (m *dequeModel) apply(op int) string {
+	cap := m.ring.Capacity()
+	n := uint(1)
+	if op >= 4 {
+		n = 3
+	}
+	fits := uint(len(m.elts))+n <= cap
+	holds := n <= uint(len(m.elts))
+	var first, second o.Range
+	var err error
+	switch op % 4 {
+	case 0:
+		first, second, err = m.ring.PushN(n)
+		if fits != (err == nil) {
+			return fmt.Sprintf("PushN(%d) on %d/%d: %v", n, len(m.elts), cap, err)
+		}
+		if err == nil {
+			m.elts = append(m.elts, m.write(first, second)...)
+		}
+	case 1:
+		first, second, err = m.ring.ShiftN(n)
+		if holds != (err == nil) {
+			return fmt.Sprintf("ShiftN(%d) on %d/%d: %v", n, len(m.elts), cap, err)
+		}
+		if err == nil {
+			if got := m.read(first, second); !reflect.DeepEqual(got, m.elts[:n]) {
+				return fmt.Sprintf("ShiftN(%d) read %v, expected %v", n, got, m.elts[:n])
+			}
+			m.elts = m.elts[n:]
+		}
+	case 2:
+		first, second, err = m.ring.UnshiftN(n)
+		if fits != (err == nil) {
+			return fmt.Sprintf("UnshiftN(%d) on %d/%d: %v", n, len(m.elts), cap, err)
+		}
+		if err == nil {
+			m.elts = append(m.write(first, second), m.elts...)
+		}
+	case 3:
+		first, second, err = m.ring.PopN(n)
+		if holds != (err == nil) {
+			return fmt.Sprintf("PopN(%d) on %d/%d: %v", n, len(m.elts), cap, err)
+		}
+		if err == nil {
+			rest := uint(len(m.elts)) - n
+			if got := m.read(first, second); !reflect.DeepEqual(got, m.elts[rest:]) {
+				return fmt.Sprintf("PopN(%d) read %v, expected %v", n, got, m.elts[rest:])
+			}
+			m.elts = m.elts[:rest]
+		}
+	}
+	return ""
+}
+
+func (m *dequeModel) check() string {
+	if err := m.ring.Check(); err != nil {
+		return err.Error()
+	}
+	if m.ring.Size() != uint(len(m.elts)) {
+		return fmt.Sprintf("size %d, expected %d", m.ring.Size(), len(m.elts))
+	}
+	fifo := []int{}
+	for s := o.ScanFIFO(m.ring); s.Next(); {
+		fifo = append(fifo, m.buf[s.Value()])
+	}
+	lifo := []int{}
+	for s := o.ScanLIFO(m.ring); s.Next(); {
+		lifo = append([]int{m.buf[s.Value()]}, lifo...)
+	}
+	if !reflect.DeepEqual(fifo, m.elts) || !reflect.DeepEqual(lifo, m.elts) {
+		return fmt.Sprintf("scanned FIFO %v and reversed LIFO %v, expected %v", fifo, lifo, m.elts)
+	}
+	return ""
+}
+
+func TestPropMixedEnds(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("Operations on both ends match a deque", prop.ForAll(
+		func(cap uint, ops []int) string {
+			m := &dequeModel{ring: o.NewRing(cap), buf: make([]int, cap), elts: []int{}}
+			for i, op := range ops {
+				if msg := m.apply(op); msg != "" {
+					return fmt.Sprintf("op %d: %s", i, msg)
+				}
+				if msg := m.check(); msg != "" {
+					return fmt.Sprintf("after op %d (%d): %s", i, op, msg)
+				}
+			}
+			return ""
+		},
+		gen.UIntRange(0, 17).WithLabel("ring size"),
+		gen.SliceOf(gen.IntRange(0, 7)).WithLabel("operations"),
+	))
+	properties.TestingRun(t)
+}
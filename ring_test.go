@@ -11,7 +11,12 @@ import (
 func TestForcePush(t *testing.T) {
 	r := NewRing(1)
 	r.Push()
-	assert.Equal(t, uint(0), r.ForcePush())
+	i, err := r.ForcePush()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), i)
+
+	_, err = NewRing(0).ForcePush()
+	assert.Equal(t, ErrFull, err)
 }
 
 func TestPushAndShift(t *testing.T) {
@@ -41,6 +46,51 @@ func TestPushAndShift(t *testing.T) {
 	}
 }
 
+func TestUnshiftAndPop(t *testing.T) {
+	tests := []struct {
+		name string
+		cap  uint
+	}{
+		{"mask", 4},
+		{"basic", 5},
+	}
+	for _, elt := range tests {
+		test := elt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ring := NewRing(test.cap)
+			pushed, err := ring.Push()
+			require.NoError(t, err)
+			require.Equal(t, uint(0), pushed)
+
+			unshifted, err := ring.Unshift()
+			require.NoError(t, err)
+			assert.Equal(t, test.cap-1, unshifted)
+
+			shifted, err := ring.Shift()
+			require.NoError(t, err)
+			assert.Equal(t, unshifted, shifted)
+
+			for i := uint(1); i < test.cap; i++ {
+				_, err = ring.Unshift()
+				require.NoError(t, err)
+			}
+			_, err = ring.Unshift()
+			assert.Equal(t, ErrFull, err)
+
+			popped, err := ring.Pop()
+			require.NoError(t, err)
+			assert.Equal(t, pushed, popped)
+			for i := uint(1); i < test.cap; i++ {
+				_, err = ring.Pop()
+				require.NoError(t, err)
+			}
+			_, err = ring.Pop()
+			assert.Equal(t, ErrEmpty, err)
+		})
+	}
+}
+
 func TestSlices(t *testing.T) {
 	tests := []struct {
 		name  string
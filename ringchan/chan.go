@@ -0,0 +1,110 @@
+// Package ringchan implements bounded channels that are backed by a
+// ring buffer, and that can discard elements instead of blocking the
+// sender when they are full.
+package ringchan
+
+import (
+	"sync/atomic"
+
+	"github.com/antifuchs/o"
+)
+
+// Policy determines what a Chan does with an element that is sent
+// while its buffer is full.
+type Policy int
+
+const (
+	// Block makes senders wait until there is room in the buffer,
+	// like a buffered go channel.
+	Block Policy = iota
+
+	// DropNewest discards the element that was just sent.
+	DropNewest
+
+	// OverwriteOldest discards the oldest buffered element to make
+	// room for the one that was just sent.
+	OverwriteOldest
+)
+
+// Chan is a bounded channel of T values, with a configurable policy
+// for what happens when its buffer is full. Values are sent on In and
+// received from Out, so a Chan can be used in select statements like
+// any other channel.
+//
+// A goroutine moves values between In and Out; it exits once In is
+// closed and all buffered values have been received, closing Out.
+type Chan[T any] struct {
+	in      chan T
+	out     chan T
+	policy  Policy
+	dropped atomic.Uint64
+}
+
+// New returns a Chan that buffers up to cap values, and starts its
+// goroutine.
+//
+// Returns o.ErrCapacity if cap is 0.
+func New[T any](cap uint, policy Policy) (*Chan[T], error) {
+	if cap == 0 {
+		return nil, o.ErrCapacity
+	}
+	c := &Chan[T]{
+		in:     make(chan T),
+		out:    make(chan T),
+		policy: policy,
+	}
+	go c.run(o.NewBuffer[T](cap))
+	return c, nil
+}
+
+// In returns the channel that values are sent on. Closing it closes
+// Out once all buffered values are received.
+func (c *Chan[T]) In() chan<- T {
+	return c.in
+}
+
+// Out returns the channel that values are received from.
+func (c *Chan[T]) Out() <-chan T {
+	return c.out
+}
+
+// Dropped returns the number of values that were discarded because
+// the buffer was full.
+func (c *Chan[T]) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+func (c *Chan[T]) run(buf *o.Buffer[T]) {
+	defer close(c.out)
+	in := c.in
+	for in != nil || !buf.Empty() {
+		recv := in
+		if c.policy == Block && buf.Full() {
+			recv = nil
+		}
+		var out chan T
+		next, err := buf.Peek()
+		if err == nil {
+			out = c.out
+		}
+
+		select {
+		case val, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+			switch {
+			case !buf.Full():
+				_ = buf.Push(val)
+			case c.policy == DropNewest:
+				c.dropped.Add(1)
+			default:
+				buf.ForcePush(val)
+				c.dropped.Add(1)
+			}
+		case out <- next:
+			_, _ = buf.Shift()
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ringchan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drain[T any](c *Chan[T]) []T {
+	vals := []T{}
+	for val := range c.Out() {
+		vals = append(vals, val)
+	}
+	return vals
+}
+
+func TestPolicies(t *testing.T) {
+	t.Parallel()
+	for _, elt := range []struct {
+		policy  Policy
+		want    []int
+		dropped uint64
+	}{
+		{DropNewest, []int{0, 1, 2}, 7},
+		{OverwriteOldest, []int{7, 8, 9}, 7},
+	} {
+		test := elt
+		c, err := New[int](3, test.policy)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			c.In() <- i
+		}
+		close(c.In())
+		assert.Equal(t, test.want, drain(c), "policy %d", test.policy)
+		assert.Equal(t, test.dropped, c.Dropped(), "policy %d", test.policy)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	t.Parallel()
+	c, err := New[int](2, Block)
+	require.NoError(t, err)
+	c.In() <- 1
+	c.In() <- 2
+	select {
+	case c.In() <- 3:
+		t.Fatal("sending to a full Chan did not block")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.Equal(t, 1, <-c.Out())
+	c.In() <- 3
+	close(c.In())
+	assert.Equal(t, []int{2, 3}, drain(c))
+	assert.Equal(t, uint64(0), c.Dropped())
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+	c, err := New[string](1, OverwriteOldest)
+	require.NoError(t, err)
+	select {
+	case val := <-c.Out():
+		t.Fatalf("received %q from an empty Chan", val)
+	default:
+	}
+	c.In() <- "hi"
+	select {
+	case val := <-c.Out():
+		assert.Equal(t, "hi", val)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestZeroCapacity(t *testing.T) {
+	t.Parallel()
+	_, err := New[int](0, Block)
+	assert.Equal(t, o.ErrCapacity, err)
+}
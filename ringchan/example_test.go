@@ -0,0 +1,26 @@
+package ringchan_test
+
+import (
+	"fmt"
+
+	"github.com/antifuchs/o/ringchan"
+)
+
+// A Chan that keeps only the most recent values, for consumers that
+// fall behind.
+func ExampleNew() {
+	c, _ := ringchan.New[int](3, ringchan.OverwriteOldest)
+	for i := 0; i < 5; i++ {
+		c.In() <- i
+	}
+	close(c.In())
+	for val := range c.Out() {
+		fmt.Println(val)
+	}
+	fmt.Println("dropped:", c.Dropped())
+	// Output:
+	// 2
+	// 3
+	// 4
+	// dropped: 2
+}
@@ -3,11 +3,26 @@
 package ringio
 
 import (
+	"bufio"
+	"context"
+	"io"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/antifuchs/o"
 )
 
+type wouldBlockErr uint
+
+func (e wouldBlockErr) Error() string {
+	return "ring buffer operation would block"
+}
+
+// ErrWouldBlock indicates a read from an empty ring buffer, or a write
+// to a ring buffer without enough room, that was created with
+// NewNonBlocking. Retrying the operation later can succeed.
+const ErrWouldBlock wouldBlockErr = iota
+
 // Bounded is an io.Reader and io.Writer that allows writing as many
 // bytes as are given for the capacity before it has to be drained by
 // reading from it.
@@ -19,6 +34,34 @@ type Bounded struct {
 	r         o.Ring
 	buf       []byte
 	overwrite bool
+	blocking  bool
+	// nonBlocking makes operations that can not proceed return
+	// ErrWouldBlock.
+	nonBlocking bool
+	// closeErr is returned by reads once the ring buffer is closed
+	// and drained; it is nil while the ring buffer is open.
+	closeErr error
+	changed  chan struct{}
+	// filling is true while ReadFrom runs, and reserving while it
+	// holds a reservation on the ring.
+	filling, reserving bool
+
+	// The bytes returned by the last ReadByte or ReadRune, for
+	// UnreadByte and UnreadRune.
+	last     [utf8.UTFMax]byte
+	lastN    int
+	lastRune bool
+
+	// Bytes discarded by overwriting writes while stats are
+	// enabled, which the ring counts as shifted (if they were on
+	// the ring) or not at all (if they were skipped in the written
+	// slice).
+	stats                bool
+	overwritten, skipped uint64
+	// Writes that found no room and reads that found no bytes
+	// while stats are enabled, which fail before they reach the
+	// ring.
+	failedPushes, failedShifts uint64
 }
 
 type byteSlice []byte
@@ -28,47 +71,256 @@ func (bs byteSlice) Len() int {
 }
 
 // New returns a bounded ring buffer of the given capacity. If
-// overwrite is true, a full ring buffer will discard unread bytes and
-// overwrite them upon writes.
+// overwrite is true, writes discard as many of the oldest unread
+// bytes as they need room for, like ForceWrite.
 //
 // If overwrite is false, writing more bytes than there is space in
 // the buffer will fail with ErrFull and no bytes will be written.
+//
+// Writes never overwrite bytes held by an outstanding PeekHold; if
+// they would have to, they fail with o.ErrPeeked and write nothing.
 func New(cap uint, overwrite bool) *Bounded {
 	buf := make([]byte, cap)
 	ring := o.NewRingForSlice(byteSlice(buf))
 	return &Bounded{r: ring, buf: buf, overwrite: overwrite}
 }
 
+// NewBlocking returns a bounded ring buffer like New, on which Read
+// and Write wait like ReadContext and WriteContext do (with a
+// background context) instead of failing or returning early. If
+// overwrite is true, writes never wait.
+func NewBlocking(cap uint, overwrite bool) *Bounded {
+	b := New(cap, overwrite)
+	b.blocking = true
+	return b
+}
+
+// NewNonBlocking returns a bounded ring buffer like New, on which
+// Read returns ErrWouldBlock instead of 0, nil if no bytes are
+// available. Write returns ErrWouldBlock instead of o.ErrFull if there
+// is not enough room for p yet, but p fits into the ring buffer's
+// capacity.
+func NewNonBlocking(cap uint, overwrite bool) *Bounded {
+	b := New(cap, overwrite)
+	b.nonBlocking = true
+	return b
+}
+
+// signal wakes up all goroutines waiting in ReadContext or
+// WriteContext. Since it is called on every change to the ring
+// buffer, it also ends the window in which UnreadByte and UnreadRune
+// can undo the last read. It must be called with the lock held.
+func (b *Bounded) signal() {
+	if b.changed != nil {
+		close(b.changed)
+	}
+	b.changed = make(chan struct{})
+	b.lastN, b.lastRune = 0, false
+}
+
+// wait releases the lock and sleeps until the ring buffer changes or
+// ctx is done.
+func (b *Bounded) wait(ctx context.Context) error {
+	if b.changed == nil {
+		b.changed = make(chan struct{})
+	}
+	changed := b.changed
+	b.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-changed:
+		return nil
+	}
+}
+
+// Write writes p to the ring buffer. On a ring buffer created with
+// NewBlocking, it waits until all of p is written, like WriteContext.
+// On one created with NewNonBlocking, it returns ErrWouldBlock instead
+// of o.ErrFull if p only needs to wait for room. Writes to a closed
+// ring buffer fail with io.ErrClosedPipe.
 func (b *Bounded) Write(p []byte) (n int, err error) {
+	if b.blocking {
+		return b.WriteContext(context.Background(), p)
+	}
 	b.Lock()
 	defer b.Unlock()
 
+	if b.closeErr != nil {
+		return 0, io.ErrClosedPipe
+	}
+	n, err = b.write(p)
+	if err == o.ErrFull && b.nonBlocking && uint(len(p)) <= b.r.Capacity() {
+		err = ErrWouldBlock
+	}
+	return
+}
+
+// WriteContext writes p to the ring buffer, waiting for readers to
+// make room as often as necessary. Unless the ring buffer overwrites
+// unread bytes, p is written in pieces as space becomes available, so
+// p may be larger than the ring buffer's capacity.
+//
+// If ctx is done before all of p could be written, WriteContext
+// returns the number of bytes written so far and ctx.Err(). On a ring
+// buffer of capacity 0 that does not overwrite, no byte can ever be
+// written, so WriteContext fails with io.ErrShortWrite instead of
+// waiting.
+func (b *Bounded) WriteContext(ctx context.Context, p []byte) (n int, err error) {
+	b.Lock()
+	for {
+		if b.closeErr != nil {
+			b.Unlock()
+			return n, io.ErrClosedPipe
+		}
+		if b.overwrite {
+			m, err := b.write(p[n:])
+			b.Unlock()
+			return n + m, err
+		}
+		if b.r.Capacity() == 0 && len(p) > 0 {
+			b.failedPush()
+			b.Unlock()
+			return 0, io.ErrShortWrite
+		}
+		free := int(b.r.Capacity() - b.r.Size())
+		if free > len(p)-n {
+			free = len(p) - n
+		}
+		if free > 0 {
+			m, err := b.write(p[n : n+free])
+			n += m
+			if err != nil {
+				b.Unlock()
+				return n, err
+			}
+		}
+		if n == len(p) {
+			b.Unlock()
+			return n, nil
+		}
+		if err = b.wait(ctx); err != nil {
+			return n, err
+		}
+		b.Lock()
+	}
+}
+
+func (b *Bounded) write(p []byte) (n int, err error) {
+	if !b.overwrite && uint(len(p)) > b.r.Capacity()-b.r.Size() {
+		b.failedPush()
+		return 0, o.ErrFull
+	}
 	n = len(p)
-	reserve := uint(len(p))
-	remaining := b.r.Capacity() - b.r.Size()
-	if remaining < uint(len(p)) {
-		if !b.overwrite {
-			return 0, o.ErrFull
+	_, err = b.forceWrite(p)
+	if err != nil {
+		return 0, err
+	}
+	return
+}
+
+// ForceWrite writes all of p to the ring buffer, regardless of
+// whether it overwrites unread bytes, and returns the number of bytes
+// that were dropped to make room: the oldest len(p) - free bytes,
+// where free is the number of free bytes on the ring buffer. If p is
+// longer than the ring buffer's capacity, that includes all bytes
+// previously on the ring buffer and the beginning of p.
+//
+// Writes in overwrite mode behave like ForceWrite. If dropping bytes
+// would drop bytes held by an outstanding PeekHold, ForceWrite fails
+// with o.ErrPeeked and writes nothing; it fails with io.ErrClosedPipe
+// if the ring buffer is closed.
+func (b *Bounded) ForceWrite(p []byte) (dropped int, err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.closeErr != nil {
+		return 0, io.ErrClosedPipe
+	}
+	return b.forceWrite(p)
+}
+
+func (b *Bounded) forceWrite(p []byte) (dropped int, err error) {
+	if b.reserving {
+		// Check up front, so that no bytes get dropped to make
+		// room for a push that can not happen.
+		return 0, o.ErrReserved
+	}
+	cap, free := b.r.Capacity(), b.r.Capacity()-b.r.Size()
+	if uint(len(p)) > free {
+		dropped = len(p) - int(free)
+		var skipped uint
+		if uint(len(p)) > cap {
+			skipped = uint(len(p)) - cap
+		}
+		if _, _, err := b.r.ShiftN(uint(dropped) - skipped); err != nil {
+			return 0, err
 		}
-		// consume the bytes that we're over and reset input
-		// to fit:
-		p = p[reserve-b.r.Capacity():]
-		for i := uint(0); i <= b.r.Size(); i++ {
-			_, _ = b.r.Shift()
+		if b.stats {
+			b.overwritten += uint64(uint(dropped) - skipped)
+			b.skipped += uint64(skipped)
 		}
-		reserve = uint(len(p))
+		p = p[skipped:]
+	}
+	first, second, err := b.r.PushN(uint(len(p)))
+	if err != nil {
+		return 0, err
 	}
-	first, second, _ := b.r.PushN(reserve)
 	copy(b.buf[first.Start:first.End], p[0:first.Length()])
 	copy(b.buf[second.Start:second.End], p[first.Length():])
-	return
+	b.signal()
+	return dropped, nil
 }
 
+// Read reads up to len(p) bytes from the ring buffer. Once the ring
+// buffer is closed and drained, Read returns io.EOF (or the error
+// passed to CloseWithError).
+//
+// If the ring buffer is empty, Read on a ring buffer created with
+// NewBlocking waits until bytes are available, like ReadContext, and
+// Read on one created with NewNonBlocking returns ErrWouldBlock.
+// Otherwise, it returns 0, nil.
 func (b *Bounded) Read(p []byte) (n int, err error) {
+	if b.blocking {
+		return b.ReadContext(context.Background(), p)
+	}
 	b.Lock()
 	defer b.Unlock()
+	return b.read(p)
+}
 
-	if b.r.Empty() {
+// ReadContext reads up to len(p) bytes from the ring buffer, waiting
+// until at least one byte is available or the ring buffer is closed.
+// Once the ring buffer is closed and drained, ReadContext returns
+// io.EOF (or the error passed to CloseWithError).
+//
+// If ctx is done before any bytes became available, ReadContext
+// returns ctx.Err().
+func (b *Bounded) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	b.Lock()
+	for b.r.Size() == 0 && b.closeErr == nil && len(p) > 0 {
+		if err = b.wait(ctx); err != nil {
+			return 0, err
+		}
+		b.Lock()
+	}
+	defer b.Unlock()
+	return b.read(p)
+}
+
+func (b *Bounded) read(p []byte) (n int, err error) {
+	if b.r.Size() == 0 {
+		// Rings of capacity 0 are never Empty, but never hold
+		// any bytes either.
+		if b.closeErr != nil {
+			return 0, b.closeErr
+		}
+		if len(p) > 0 {
+			b.failedShift()
+		}
+		if b.nonBlocking && len(p) > 0 {
+			return 0, ErrWouldBlock
+		}
 		return 0, nil
 	}
 
@@ -78,13 +330,127 @@ func (b *Bounded) Read(p []byte) (n int, err error) {
 	}
 	var first, second o.Range
 	first, second, err = b.r.ShiftN(uint(n))
+	if err != nil {
+		return 0, err
+	}
 	copy(p[0:first.Length()], b.buf[first.Start:first.End])
 	copy(p[first.Length():], b.buf[second.Start:second.End])
+	b.signal()
 	return
 }
 
+// Close closes the writing side of the ring buffer: Writes fail with
+// io.ErrClosedPipe from now on, and once the remaining bytes are
+// read, reads return io.EOF. Goroutines waiting in ReadContext or
+// WriteContext are woken up.
+func (b *Bounded) Close() error {
+	return b.CloseWithError(nil)
+}
+
+// CloseWithError closes the writing side of the ring buffer like
+// Close, but makes reads return err instead of io.EOF once the
+// remaining bytes are read. A nil err is equivalent to io.EOF.
+//
+// Closing a ring buffer that is already closed does not change the
+// error that reads return.
+func (b *Bounded) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	b.Lock()
+	defer b.Unlock()
+	if b.closeErr == nil {
+		b.closeErr = err
+	}
+	b.signal()
+	return nil
+}
+
+// Peek returns up to n of the oldest readable bytes on the ring
+// buffer without consuming or holding them, as two slices that share
+// the ring buffer's storage (the second one may be empty).
+//
+// The slices are only valid until the next write to the ring buffer,
+// which may overwrite or move the bytes they point to. To keep the
+// bytes around, use Snapshot, or PeekHold to keep writers away from
+// them.
+func (b *Bounded) Peek(n int) (first, second []byte) {
+	b.Lock()
+	defer b.Unlock()
+
+	if size := int(b.r.Size()); n > size {
+		n = size
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+	f, s, _ := b.r.Window(0, n)
+	return b.buf[f.Start:f.End], b.buf[s.Start:s.End]
+}
+
+// PeekHold returns up to n of the oldest readable bytes on the ring
+// buffer without consuming them, as two slices that share the ring
+// buffer's storage (the second one may be empty).
+//
+// The held bytes stay valid until they are handed back with Release:
+// Writes will not overwrite them, and Reads fail with o.ErrPeeked in
+// the meantime. Calling PeekHold again replaces the previous hold;
+// Reset and Bytes end it.
+//
+// To look at the readable bytes without holding them, use Snapshot,
+// or call Release(0) when done with the held slices.
+func (b *Bounded) PeekHold(n int) (first, second []byte) {
+	b.Lock()
+	defer b.Unlock()
+
+	if size := int(b.r.Size()); n > size {
+		n = size
+	}
+	f, s, _ := b.r.PeekN(uint(n))
+	return b.buf[f.Start:f.End], b.buf[s.Start:s.End]
+}
+
+// Release consumes the first n bytes returned by the outstanding
+// PeekHold and ends the hold. Release(0) ends the hold without
+// consuming any bytes.
+//
+// Release panics if n exceeds the number of held bytes.
+func (b *Bounded) Release(n int) {
+	b.Lock()
+	defer b.Unlock()
+	b.r.Release(uint(n))
+	b.signal()
+}
+
+// Resize changes the capacity of the ring buffer, keeping all
+// readable bytes. If more bytes are readable than fit into the new
+// capacity, Resize fails with o.ErrShrink, unless dropOldest is set:
+// In that case, only the newest bytes are kept.
+//
+// Resizing fails with o.ErrPeeked while a PeekHold is outstanding.
+func (b *Bounded) Resize(cap uint, dropOldest bool) error {
+	b.Lock()
+	defer b.Unlock()
+
+	ring, plan, err := b.r.Resize(cap, dropOldest)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, cap)
+	for _, step := range plan {
+		copy(buf[step.To.Start:step.To.End], b.buf[step.From.Start:step.From.End])
+	}
+	b.r, b.buf = ring, buf
+	if b.stats {
+		// Rings of capacity 0 keep no statistics to carry over.
+		b.r.EnableStats()
+	}
+	b.signal()
+	return nil
+}
+
 func (b *Bounded) reset() {
-	b.r = o.NewRingForSlice(byteSlice(b.buf))
+	b.r.Consume()
 }
 
 // Reset throws away all data on the ring buffer.
@@ -92,6 +458,67 @@ func (b *Bounded) Reset() {
 	b.Lock()
 	defer b.Unlock()
 	b.reset()
+	b.signal()
+}
+
+// EnableStats starts counting the bytes written to and read from the
+// ring buffer. See o.Ring.EnableStats.
+func (b *Bounded) EnableStats() {
+	b.Lock()
+	defer b.Unlock()
+	b.r.EnableStats()
+	b.stats = true
+}
+
+// Stats returns the ring buffer's operation counters, in bytes. Bytes
+// that overwriting writes discard count as overwritten, including
+// those that are skipped because a single write is larger than the
+// ring buffer's capacity. Writes (and ReadFrom calls) that fail for
+// lack of room count as failed pushes, and reads that return early
+// because the open ring buffer holds no (or, for ReadRune, too few)
+// bytes count as failed shifts. If statistics are not enabled, all
+// counters are zero.
+func (b *Bounded) Stats() o.Stats {
+	b.Lock()
+	defer b.Unlock()
+	stats := b.r.Stats()
+	// Overwritten bytes are shifted off the ring, but not read.
+	if stats.Shifted >= b.overwritten {
+		stats.Shifted -= b.overwritten
+	} else {
+		stats.Shifted = 0
+	}
+	stats.Pushed += b.skipped
+	stats.Overwritten += b.overwritten + b.skipped
+	stats.FailedPushes += b.failedPushes
+	stats.FailedShifts += b.failedShifts
+	return stats
+}
+
+// failedPush counts a write that found no room, if statistics are
+// enabled. It must be called with the lock held.
+func (b *Bounded) failedPush() {
+	if b.stats {
+		b.failedPushes++
+	}
+}
+
+// failedShift counts a read that found too few bytes, if statistics
+// are enabled. It must be called with the lock held.
+func (b *Bounded) failedShift() {
+	if b.stats {
+		b.failedShifts++
+	}
+}
+
+// ResetStats sets the ring buffer's operation counters back to zero.
+// See o.Ring.ResetStats.
+func (b *Bounded) ResetStats() {
+	b.Lock()
+	defer b.Unlock()
+	b.r.ResetStats()
+	b.overwritten, b.skipped = 0, 0
+	b.failedPushes, b.failedShifts = 0, 0
 }
 
 // Bytes consumes all readable data on the ring buffer and returns a
@@ -101,12 +528,64 @@ func (b *Bounded) Bytes() []byte {
 	defer b.Unlock()
 
 	first, second := b.r.Consume()
+	b.signal()
+	return b.copyRanges(first, second)
+}
+
+// Snapshot returns a newly-allocated byte slice containing all
+// readable bytes on the ring buffer, without consuming them.
+func (b *Bounded) Snapshot() []byte {
+	b.Lock()
+	defer b.Unlock()
+	return b.copyRanges(b.r.Inspect())
+}
+
+func (b *Bounded) copyRanges(first, second o.Range) []byte {
 	val := make([]byte, first.Length()+second.Length())
 	copy(val, b.buf[first.Start:first.End])
-	copy(val[first.End:], b.buf[second.Start:second.End])
+	copy(val[first.Length():], b.buf[second.Start:second.End])
 	return val
 }
 
+// Discard consumes the next n readable bytes without copying them,
+// and returns the number of bytes discarded. If fewer than n bytes
+// are readable, Discard consumes all of them and returns an error
+// like ReadByte would on an empty ring buffer, without waiting.
+//
+// Discard fails with o.ErrPeeked while a PeekHold is outstanding, and
+// with bufio.ErrNegativeCount if n is negative.
+func (b *Bounded) Discard(n int) (discarded int, err error) {
+	if n < 0 {
+		return 0, bufio.ErrNegativeCount
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	discarded = n
+	if size := int(b.r.Size()); discarded > size {
+		discarded = size
+	}
+	if _, _, err = b.r.ShiftN(uint(discarded)); err != nil {
+		return 0, err
+	}
+	if discarded > 0 {
+		b.signal()
+	}
+	if discarded < n {
+		switch {
+		case b.closeErr != nil:
+			err = b.closeErr
+		case b.nonBlocking:
+			b.failedShift()
+			err = ErrWouldBlock
+		default:
+			b.failedShift()
+			err = o.ErrEmpty
+		}
+	}
+	return discarded, err
+}
+
 // String consumes all readable data on the ring buffer and returns it
 // as a string.
 func (b *Bounded) String() string {
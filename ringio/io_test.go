@@ -1,7 +1,14 @@
 package ringio
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/antifuchs/o"
 	"github.com/stretchr/testify/assert"
@@ -141,3 +148,480 @@ func TestZero(t *testing.T) {
 	assert.Equal(t, o.ErrFull, err)
 	assert.Equal(t, 0, n)
 }
+
+func TestZeroModes(t *testing.T) {
+	t.Parallel()
+	b := New(0, false)
+	n, err := b.Read(make([]byte, 4))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	require.NoError(t, b.Close())
+	_, err = b.Read(make([]byte, 4))
+	assert.Equal(t, io.EOF, err, "closed ring buffers of capacity 0 are at EOF")
+
+	b = New(0, true)
+	n, err = b.Write([]byte("welp"))
+	assert.NoError(t, err, "overwriting drops all bytes")
+	assert.Equal(t, 4, n)
+	n, err = b.Read(make([]byte, 4))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	b = NewNonBlocking(0, false)
+	n, err = b.Read(make([]byte, 4))
+	assert.Equal(t, ErrWouldBlock, err)
+	assert.Equal(t, 0, n)
+	_, err = b.Write([]byte("welp"))
+	assert.Equal(t, o.ErrFull, err, "no write can ever fit")
+	require.NoError(t, b.Close())
+	_, err = b.Read(make([]byte, 4))
+	assert.Equal(t, io.EOF, err)
+
+	b = NewBlocking(0, false)
+	n, err = b.Write([]byte("welp"))
+	assert.Equal(t, io.ErrShortWrite, err, "writes must not block forever")
+	assert.Equal(t, 0, n)
+	n, err = b.Write(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = b.ReadContext(ctx, make([]byte, 4))
+	assert.Equal(t, context.DeadlineExceeded, err, "reads wait for data or Close")
+	done := make(chan error)
+	go func() {
+		_, err := b.Read(make([]byte, 4))
+		done <- err
+	}()
+	require.NoError(t, b.Close())
+	assert.Equal(t, io.EOF, <-done)
+}
+
+func TestPeekHoldRelease(t *testing.T) {
+	t.Parallel()
+	b := New(8, true)
+	_, err := b.Write([]byte("0123456789"))
+	require.NoError(t, err)
+
+	first, second := b.PeekHold(5)
+	assert.Equal(t, "23456", string(first)+string(second))
+
+	n, err := b.Write([]byte("abc"))
+	assert.Equal(t, o.ErrPeeked, err, "overwriting would clobber peeked bytes")
+	assert.Equal(t, 0, n)
+	n, err = b.Read(make([]byte, 1))
+	assert.Equal(t, o.ErrPeeked, err)
+	assert.Equal(t, 0, n)
+
+	b.Release(3)
+	n, err = b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	first, second = b.PeekHold(100)
+	assert.Equal(t, "56789abc", string(first)+string(second))
+	assert.NotEmpty(t, second, "peek wraps around")
+	b.Release(0)
+	read := make([]byte, 8)
+	n, err = b.Read(read)
+	require.NoError(t, err)
+	assert.Equal(t, "56789abc", string(read[:n]))
+}
+
+func TestResize(t *testing.T) {
+	t.Parallel()
+	b := New(6, true)
+	_, err := b.Write([]byte("abcdefgh"))
+	require.NoError(t, err)
+
+	require.NoError(t, b.Resize(16, false))
+	n, err := b.Write([]byte("ijkl"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	assert.Equal(t, o.ErrShrink, b.Resize(5, false))
+	require.NoError(t, b.Resize(5, true))
+
+	read := make([]byte, 16)
+	n, err = b.Read(read)
+	require.NoError(t, err)
+	assert.Equal(t, "hijkl", string(read[:n]))
+}
+
+func TestBlockingTransfer(t *testing.T) {
+	t.Parallel()
+	b := NewBlocking(5, false)
+	input := bytes.Repeat([]byte("0123456789"), 100)
+	go func() {
+		n, err := b.Write(input)
+		if err != nil || n != len(input) {
+			panic(err)
+		}
+		_ = b.Close()
+	}()
+
+	output, err := io.ReadAll(b)
+	require.NoError(t, err)
+	assert.Equal(t, input, output)
+
+	_, err = b.Write([]byte("x"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+func TestContextCancel(t *testing.T) {
+	t.Parallel()
+	b := New(3, false)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	n, err := b.ReadContext(ctx, make([]byte, 3))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 0, n)
+
+	n, err = b.WriteContext(ctx, []byte("abcde"))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 3, n, "writes as much as fits")
+
+	n, err = b.Read(make([]byte, 3))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = b.Read(make([]byte, 3))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "non-blocking reads don't wait")
+}
+
+func TestCloseDrains(t *testing.T) {
+	t.Parallel()
+	b := New(8, false)
+	_, err := b.Write([]byte("abc"))
+	require.NoError(t, err)
+
+	read := make(chan string)
+	go func() {
+		buf := make([]byte, 8)
+		n, _ := b.ReadContext(context.Background(), buf)
+		read <- string(buf[:n])
+	}()
+	assert.Equal(t, "abc", <-read)
+
+	waiting := make(chan error)
+	go func() {
+		_, err := b.ReadContext(context.Background(), make([]byte, 8))
+		waiting <- err
+	}()
+	require.NoError(t, b.Close())
+	assert.Equal(t, io.EOF, <-waiting)
+	n, err := b.Read(make([]byte, 8))
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+	b := New(4, true)
+	_, err := b.Write([]byte("ab"))
+	require.NoError(t, err)
+	assert.Equal(t, o.Stats{}, b.Stats())
+
+	b.EnableStats()
+	_, err = b.Write([]byte("cdefgh"))
+	require.NoError(t, err)
+	n, err := b.Read(make([]byte, 3))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, o.Stats{
+		Pushed:      6,
+		Shifted:     3,
+		Overwritten: 4,
+		HighWater:   4,
+	}, b.Stats())
+
+	b.ResetStats()
+	assert.Equal(t, o.Stats{HighWater: 1}, b.Stats())
+	b.Reset()
+	assert.Equal(t, o.Stats{Shifted: 1, HighWater: 1}, b.Stats())
+}
+
+func TestStatsFailures(t *testing.T) {
+	t.Parallel()
+	b := New(4, false)
+	b.EnableStats()
+	n, err := b.Read(make([]byte, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	_, err = b.ReadByte()
+	assert.Equal(t, o.ErrEmpty, err)
+	_, _, err = b.ReadRune()
+	assert.Equal(t, o.ErrEmpty, err)
+	_, err = b.Read(nil)
+	require.NoError(t, err, "empty reads don't fail")
+
+	_, err = b.Write([]byte("abc"))
+	require.NoError(t, err)
+	_, err = b.Write([]byte("de"))
+	assert.Equal(t, o.ErrFull, err)
+	_, err = b.WriteString("0123456789")
+	assert.Equal(t, o.ErrFull, err)
+	_, err = b.Discard(5)
+	assert.Equal(t, o.ErrEmpty, err)
+	assert.Equal(t, o.Stats{
+		Pushed:       3,
+		Shifted:      3,
+		FailedPushes: 2,
+		FailedShifts: 4,
+		HighWater:    3,
+	}, b.Stats())
+
+	b.ResetStats()
+	assert.Equal(t, o.Stats{}, b.Stats())
+	require.NoError(t, b.Close())
+	_, err = b.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, o.Stats{}, b.Stats(), "reads at EOF don't fail")
+
+	nb := NewNonBlocking(2, false)
+	nb.EnableStats()
+	_, err = nb.Read(make([]byte, 1))
+	assert.Equal(t, ErrWouldBlock, err)
+	_, err = nb.Write([]byte("abc"))
+	assert.Equal(t, o.ErrFull, err)
+	_, err = nb.Write([]byte("ab"))
+	require.NoError(t, err)
+	_, err = nb.Write([]byte("c"))
+	assert.Equal(t, ErrWouldBlock, err)
+	_, err = nb.ReadFrom(strings.NewReader("c"))
+	assert.Equal(t, ErrWouldBlock, err)
+	stats := nb.Stats()
+	assert.Equal(t, uint64(3), stats.FailedPushes)
+	assert.Equal(t, uint64(1), stats.FailedShifts)
+}
+
+func TestStatsAcrossRings(t *testing.T) {
+	t.Parallel()
+	b := New(0, true)
+	b.EnableStats()
+	_, err := b.Write([]byte("ab"))
+	require.NoError(t, err)
+	require.NoError(t, b.Resize(4, false))
+	_, err = b.Write([]byte("cdefgh"))
+	require.NoError(t, err)
+	_, err = b.Write([]byte("ij"))
+	require.NoError(t, err)
+	assert.Equal(t, o.Stats{
+		Pushed:      10,
+		Overwritten: 6,
+		HighWater:   4,
+	}, b.Stats(), "counting continues on the resized ring")
+
+	n, err := b.Read(make([]byte, 8))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	_, err = b.Read(make([]byte, 1))
+	require.NoError(t, err)
+	data, err := b.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, b.UnmarshalBinary(data))
+	assert.Equal(t, o.Stats{}, b.Stats(), "decoding starts over")
+	_, err = b.Write([]byte("klmnop"))
+	require.NoError(t, err)
+	assert.Equal(t, o.Stats{
+		Pushed:      6,
+		Overwritten: 2,
+		HighWater:   4,
+	}, b.Stats())
+}
+
+func TestOverwritePartial(t *testing.T) {
+	t.Parallel()
+	b := New(9, true)
+	_, err := b.Write([]byte("abcdef"))
+	require.NoError(t, err)
+
+	// Shorter than the capacity, but longer than the free space:
+	n, err := b.Write([]byte("ghijk"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	read := make([]byte, 9)
+	n, err = b.Read(read)
+	require.NoError(t, err)
+	assert.Equal(t, "cdefghijk", string(read[:n]), "only the oldest bytes are dropped")
+
+	_, err = b.Write([]byte("lmnop"))
+	require.NoError(t, err)
+	dropped, err := b.ForceWrite([]byte("qrstuvwxyz"))
+	require.NoError(t, err)
+	assert.Equal(t, 6, dropped)
+	n, err = b.Read(read)
+	require.NoError(t, err)
+	assert.Equal(t, "rstuvwxyz", string(read[:n]))
+
+	strict := New(3, false)
+	dropped, err = strict.ForceWrite([]byte("abcd"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, dropped, "ForceWrite overwrites regardless of mode")
+}
+
+func TestNonBlocking(t *testing.T) {
+	t.Parallel()
+	b := NewNonBlocking(4, false)
+	buf := make([]byte, 4)
+	n, err := b.Read(buf)
+	assert.Equal(t, ErrWouldBlock, err)
+	assert.Equal(t, 0, n)
+	n, err = b.Read(nil)
+	assert.NoError(t, err, "empty reads never block")
+	assert.Equal(t, 0, n)
+
+	_, err = b.Write([]byte("abc"))
+	require.NoError(t, err)
+	_, err = b.Write([]byte("de"))
+	assert.Equal(t, ErrWouldBlock, err)
+	_, err = b.Write([]byte("defgh"))
+	assert.Equal(t, o.ErrFull, err, "never fits")
+
+	n, err = b.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(buf[:n]))
+	require.NoError(t, b.Close())
+	n, err = b.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestCloseWithError(t *testing.T) {
+	t.Parallel()
+	b := New(4, false)
+	_, err := b.Write([]byte("ab"))
+	require.NoError(t, err)
+	failure := errors.New("upstream failed")
+	require.NoError(t, b.CloseWithError(failure))
+	require.NoError(t, b.Close(), "closing again keeps the error")
+
+	_, err = b.Write([]byte("c"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+	_, err = b.ForceWrite([]byte("c"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+
+	got, err := io.ReadAll(b)
+	assert.Equal(t, failure, err)
+	assert.Equal(t, "ab", string(got))
+}
+
+func TestReadAll(t *testing.T) {
+	t.Parallel()
+	b := New(16, false)
+	_, err := b.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+	got, err := io.ReadAll(b)
+	require.NoError(t, err, "io.EOF ends the stream")
+	assert.Equal(t, "hello", string(got))
+}
+
+// wrapped returns a ring buffer of capacity 8 whose readable bytes
+// "cdefghij" wrap around the end of its backing buffer.
+func wrapped(t *testing.T) *Bounded {
+	b := New(8, false)
+	_, err := b.Write([]byte("abcdef"))
+	require.NoError(t, err)
+	_, err = b.Read(make([]byte, 2))
+	require.NoError(t, err)
+	_, err = b.Write([]byte("ghij"))
+	require.NoError(t, err)
+	return b
+}
+
+func TestBytesWrapped(t *testing.T) {
+	t.Parallel()
+	b := wrapped(t)
+	assert.Equal(t, "cdefghij", string(b.Bytes()))
+	assert.Equal(t, "", b.String(), "Bytes consumes")
+
+	b = wrapped(t)
+	assert.Equal(t, "cdefghij", b.String())
+}
+
+func TestSnapshotWrapped(t *testing.T) {
+	t.Parallel()
+	b := wrapped(t)
+	snap := b.Snapshot()
+	assert.Equal(t, "cdefghij", string(snap))
+	snap[0] = 'X'
+	assert.Equal(t, "cdefghij", string(b.Snapshot()), "snapshots are copies")
+	assert.Equal(t, "cdefghij", b.String(), "Snapshot does not consume")
+}
+
+func TestPeekHoldWrapped(t *testing.T) {
+	t.Parallel()
+	b := wrapped(t)
+	first, second := b.PeekHold(7)
+	assert.Equal(t, "cdefgh", string(first))
+	assert.Equal(t, "i", string(second))
+	b.Release(0)
+	first, second = b.PeekHold(3)
+	assert.Equal(t, "cde", string(first))
+	assert.Empty(t, second)
+	b.Release(0)
+	assert.Equal(t, "cdefghij", string(b.Snapshot()), "peeking does not consume")
+}
+
+func TestPeekWrapped(t *testing.T) {
+	t.Parallel()
+	b := wrapped(t)
+	first, second := b.Peek(7)
+	assert.Equal(t, "cdefgh", string(first))
+	assert.Equal(t, "i", string(second))
+	first, second = b.Peek(3)
+	assert.Equal(t, "cde", string(first))
+	assert.Empty(t, second)
+	first, second = b.Peek(100)
+	assert.Equal(t, "cdefghij", string(first)+string(second))
+	first, second = b.Peek(0)
+	assert.Empty(t, first)
+	assert.Empty(t, second)
+	first, second = b.Peek(-1)
+	assert.Empty(t, first)
+	assert.Empty(t, second)
+
+	read := make([]byte, 4)
+	n, err := b.Read(read)
+	require.NoError(t, err, "Peek holds nothing")
+	assert.Equal(t, "cdef", string(read[:n]))
+	_, err = b.Write([]byte("kl"))
+	require.NoError(t, err)
+	first, second = b.Peek(100)
+	assert.Equal(t, "gh", string(first))
+	assert.Equal(t, "ijkl", string(second))
+	n, err = b.Discard(2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	first, second = b.Peek(100)
+	assert.Equal(t, "ijkl", string(first)+string(second))
+}
+
+func TestDiscardWrapped(t *testing.T) {
+	t.Parallel()
+	b := wrapped(t)
+	n, err := b.Discard(5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, "hij", string(b.Snapshot()))
+
+	n, err = b.Discard(5)
+	assert.Equal(t, o.ErrEmpty, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "", string(b.Snapshot()))
+
+	_, err = b.Discard(-1)
+	assert.Equal(t, bufio.ErrNegativeCount, err)
+
+	b = wrapped(t)
+	b.PeekHold(2)
+	_, err = b.Discard(1)
+	assert.Equal(t, o.ErrPeeked, err)
+	b.Release(0)
+	require.NoError(t, b.Close())
+	n, err = b.Discard(10)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 8, n)
+}
@@ -0,0 +1,99 @@
+package ringio
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/antifuchs/o"
+)
+
+// The serialized form of a Bounded is, in order:
+//
+//	magic     [2]byte  "ob"
+//	version   uint8    (currently 1)
+//	overwrite uint8    1 if the buffer overwrites, 0 otherwise
+//	ringLen   uint32   big-endian, the length of the ring encoding
+//	ring      [ringLen]byte, as encoded by o.Ring.MarshalBinary
+//	buf       the backing buffer, exactly as long as the capacity
+const (
+	boundedMagic0, boundedMagic1 = 'o', 'b'
+	boundedVersion               = 1
+	boundedHeaderLen             = 8
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. It encodes the
+// ring buffer's contents, accounting state and overwrite flag in a
+// versioned, architecture-independent format.
+//
+// An outstanding PeekHold is not encoded: The held bytes are readable
+// in a decoded Bounded.
+func (b *Bounded) MarshalBinary() ([]byte, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	ring, err := b.r.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, boundedHeaderLen, boundedHeaderLen+len(ring)+len(b.buf))
+	data[0], data[1], data[2] = boundedMagic0, boundedMagic1, boundedVersion
+	if b.overwrite {
+		data[3] = 1
+	}
+	binary.BigEndian.PutUint32(data[4:], uint32(len(ring)))
+	data = append(data, ring...)
+	data = append(data, b.buf...)
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a
+// Bounded encoded by MarshalBinary, including its readable bytes.
+// Data that is truncated, of an unknown version, or that describes an
+// impossible state is rejected with an error wrapping o.ErrInvalid,
+// and leaves b unchanged. Statistics are not encoded; if they are
+// enabled on b, they start over from zero.
+//
+// UnmarshalBinary fails with o.ErrReserved while a ReadFrom is in
+// progress on b.
+func (b *Bounded) UnmarshalBinary(data []byte) error {
+	if len(data) < boundedHeaderLen {
+		return fmt.Errorf("%w: encoded buffer is truncated", o.ErrInvalid)
+	}
+	if data[0] != boundedMagic0 || data[1] != boundedMagic1 {
+		return fmt.Errorf("%w: not an encoded ring buffer", o.ErrInvalid)
+	}
+	if data[2] != boundedVersion {
+		return fmt.Errorf("%w: unsupported encoding version %d", o.ErrInvalid, data[2])
+	}
+	if data[3] > 1 {
+		return fmt.Errorf("%w: bad overwrite flag %d", o.ErrInvalid, data[3])
+	}
+	ringLen := uint64(binary.BigEndian.Uint32(data[4:]))
+	if ringLen > uint64(len(data)-boundedHeaderLen) {
+		return fmt.Errorf("%w: encoded buffer is truncated", o.ErrInvalid)
+	}
+	var ring o.Ring
+	if err := ring.UnmarshalBinary(data[boundedHeaderLen : boundedHeaderLen+ringLen]); err != nil {
+		return err
+	}
+	contents := data[boundedHeaderLen+ringLen:]
+	if uint64(len(contents)) != uint64(ring.Capacity()) {
+		return fmt.Errorf("%w: %d bytes of contents for capacity %d", o.ErrInvalid, len(contents), ring.Capacity())
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	if b.reserving || b.filling {
+		return o.ErrReserved
+	}
+	b.r = ring
+	b.buf = append([]byte{}, contents...)
+	b.overwrite = data[3] == 1
+	if b.stats {
+		b.r.EnableStats()
+		b.overwritten, b.skipped = 0, 0
+		b.failedPushes, b.failedShifts = 0, 0
+	}
+	b.signal()
+	return nil
+}
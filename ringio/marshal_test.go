@@ -0,0 +1,122 @@
+package ringio
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, overwrite := range []bool{true, false} {
+		b := New(10, true)
+		_, err := b.Write([]byte("hello there, world"))
+		require.NoError(t, err)
+		read := make([]byte, 3)
+		_, err = b.Read(read)
+		require.NoError(t, err)
+		b.overwrite = overwrite
+
+		data, err := b.MarshalBinary()
+		require.NoError(t, err)
+
+		var decoded Bounded
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, overwrite, decoded.overwrite)
+
+		read = make([]byte, 10)
+		n, err := decoded.Read(read)
+		require.NoError(t, err)
+		assert.Equal(t, ", world", string(read[:n]))
+	}
+}
+
+func TestUnmarshalRejectsInvalid(t *testing.T) {
+	t.Parallel()
+	b := New(8, false)
+	_, err := b.Write([]byte("hi"))
+	require.NoError(t, err)
+	valid, err := b.MarshalBinary()
+	require.NoError(t, err)
+	corrupt := func(at int, val byte) []byte {
+		data := append([]byte{}, valid...)
+		data[at] = val
+		return data
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":          nil,
+		"magic":          corrupt(1, 'r'),
+		"version":        corrupt(2, 9),
+		"overwrite flag": corrupt(3, 2),
+		"ring length":    corrupt(7, 200),
+		"ring state":     corrupt(boundedHeaderLen+27, 9),
+		"contents":       valid[:len(valid)-1],
+	} {
+		decoded := New(3, true)
+		err := decoded.UnmarshalBinary(data)
+		assert.True(t, errors.Is(err, o.ErrInvalid), "%s: unexpected error %v", name, err)
+		assert.Len(t, decoded.buf, 3, "%s: buffer is unchanged", name)
+	}
+}
+
+func TestUnmarshalDuringReadFrom(t *testing.T) {
+	t.Parallel()
+	src := New(8, false)
+	_, err := src.Write([]byte("xyz"))
+	require.NoError(t, err)
+	data, err := src.MarshalBinary()
+	require.NoError(t, err)
+
+	for _, overwrite := range []bool{false, true} {
+		b := New(4, overwrite)
+		if overwrite {
+			// Full, so ReadFrom reads into its scratch buffer.
+			_, err = b.Write([]byte("abcd"))
+			require.NoError(t, err)
+		}
+		r := &gatedReader{reading: make(chan struct{}), gate: make(chan []byte)}
+		done := make(chan error)
+		go func() {
+			_, err := b.ReadFrom(r)
+			done <- err
+		}()
+		<-r.reading
+		assert.Equal(t, o.ErrReserved, b.UnmarshalBinary(data), "overwrite=%t", overwrite)
+
+		r.gate <- []byte("ij")
+		<-r.reading
+		close(r.gate)
+		require.NoError(t, <-done)
+		require.NoError(t, b.UnmarshalBinary(data), "overwrite=%t", overwrite)
+		assert.Equal(t, "xyz", string(b.Snapshot()), "overwrite=%t", overwrite)
+	}
+}
+
+func TestUnmarshalWakesReaders(t *testing.T) {
+	t.Parallel()
+	src := New(8, false)
+	_, err := src.Write([]byte("xyz"))
+	require.NoError(t, err)
+	data, err := src.MarshalBinary()
+	require.NoError(t, err)
+
+	b := NewBlocking(8, false)
+	done := make(chan string)
+	go func() {
+		read := make([]byte, 8)
+		n, err := b.Read(read)
+		if err != nil && err != io.EOF {
+			panic(err)
+		}
+		done <- string(read[:n])
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, b.UnmarshalBinary(data))
+	assert.Equal(t, "xyz", <-done)
+}
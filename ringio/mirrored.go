@@ -0,0 +1,189 @@
+package ringio
+
+import (
+	"os"
+	"sync"
+
+	"github.com/antifuchs/o"
+)
+
+// Mirrored is a ring buffer whose readable and writable regions are
+// always available as one contiguous slice each, even when they wrap
+// around the end of the buffer.
+//
+// Where the operating system supports it (currently on Linux), this
+// is done by mapping the same memory twice, back-to-back, so that the
+// byte after the last one in the buffer is the first one again.
+// Elsewhere, or if mapping the memory fails, Mirrored falls back to
+// keeping a second copy of the buffer in memory, which costs an
+// additional copy of every byte written.
+//
+// Its accounting is done by an o.Ring, protected by a Mutex.
+type Mirrored struct {
+	sync.Mutex
+	r o.Ring
+
+	// buf is twice as long as the ring's capacity. Its second
+	// half always holds the same bytes as the first half.
+	buf    []byte
+	mapped bool
+	unmap  func() error
+}
+
+// NewMirrored returns a Mirrored ring buffer that can hold at least
+// cap bytes. The capacity is rounded up to a multiple of the memory
+// page size.
+//
+// A Mirrored ring buffer may hold memory that is not managed by the
+// garbage collector, and that is only released by Close: Callers must
+// Close the buffer once neither it nor any slice it returned is in
+// use anymore. (No finalizer does this, because the slices can
+// outlive the Mirrored they came from.)
+func NewMirrored(cap uint) *Mirrored {
+	return newMirrored(cap, mapMirrored)
+}
+
+// newMirrored returns a Mirrored ring buffer whose memory is mapped by
+// mapper, or copied if mapper is nil or fails.
+func newMirrored(cap uint, mapper func(size int) ([]byte, func() error, error)) *Mirrored {
+	if page := uint(os.Getpagesize()); cap%page != 0 {
+		cap += page - cap%page
+	}
+	m := &Mirrored{r: o.NewRing(cap)}
+	if mapper != nil && cap > 0 {
+		buf, unmap, err := mapper(int(cap))
+		if err == nil {
+			m.buf, m.unmap, m.mapped = buf, unmap, true
+			return m
+		}
+	}
+	m.buf = make([]byte, 2*cap)
+	return m
+}
+
+// Mapped returns whether the buffer is mirrored by mapping its memory
+// twice (true), or by keeping a second copy of it (false).
+func (m *Mirrored) Mapped() bool {
+	return m.mapped
+}
+
+// Capacity returns the number of bytes the ring buffer can hold.
+func (m *Mirrored) Capacity() uint {
+	return m.r.Capacity()
+}
+
+// Size returns the number of readable bytes on the ring buffer.
+func (m *Mirrored) Size() uint {
+	m.Lock()
+	defer m.Unlock()
+	return m.r.Size()
+}
+
+// ReadableSlice returns all readable bytes on the ring buffer, oldest
+// first, as one slice sharing the ring buffer's storage. Call Release
+// once they have been processed.
+//
+// The slice stays valid until the bytes in it are released; writes to
+// the ring buffer do not affect it.
+func (m *Mirrored) ReadableSlice() []byte {
+	m.Lock()
+	defer m.Unlock()
+	first, _ := m.r.Inspect()
+	return m.buf[first.Start : first.Start+m.r.Size()]
+}
+
+// WritableSlice returns the free space on the ring buffer as one slice
+// sharing the ring buffer's storage. Fill it from the start, and call
+// Commit to make the written bytes readable.
+//
+// The slice stays valid until the next Commit; reads from the ring
+// buffer do not affect it.
+func (m *Mirrored) WritableSlice() []byte {
+	m.Lock()
+	defer m.Unlock()
+	free := m.r.Capacity() - m.r.Size()
+	if free == 0 {
+		return m.buf[:0]
+	}
+	first, _, _ := m.r.Reserve(free)
+	m.r.Abort()
+	return m.buf[first.Start : first.Start+free]
+}
+
+// Commit makes the first n bytes of the slice returned by
+// WritableSlice readable. Returns o.ErrFull if there is not enough
+// free space for n bytes.
+func (m *Mirrored) Commit(n int) error {
+	m.Lock()
+	defer m.Unlock()
+	return m.commit(uint(n))
+}
+
+func (m *Mirrored) commit(n uint) error {
+	first, second, err := m.r.Reserve(n)
+	if err != nil {
+		return err
+	}
+	if !m.mapped {
+		// WritableSlice hands out the first range in the
+		// buffer's first half and the (wrapped-around) second
+		// range in its second half; copy each to the other.
+		cap := m.r.Capacity()
+		copy(m.buf[first.Start+cap:first.End+cap], m.buf[first.Start:first.End])
+		copy(m.buf[second.Start:second.End], m.buf[second.Start+cap:second.End+cap])
+	}
+	m.r.Commit(n)
+	return nil
+}
+
+// Release discards the n oldest readable bytes, freeing their space
+// for writing. Returns o.ErrEmpty if fewer than n bytes are readable.
+func (m *Mirrored) Release(n int) error {
+	m.Lock()
+	defer m.Unlock()
+	_, _, err := m.r.ShiftN(uint(n))
+	return err
+}
+
+// Write implements io.Writer. If p does not fit into the free space
+// on the ring buffer, Write fails with o.ErrFull and writes nothing.
+func (m *Mirrored) Write(p []byte) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+	n := uint(len(p))
+	if n > m.r.Capacity()-m.r.Size() {
+		return 0, o.ErrFull
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	first, _, _ := m.r.Reserve(n)
+	copy(m.buf[first.Start:first.Start+n], p)
+	return len(p), m.commit(n)
+}
+
+// Read implements io.Reader. It reads up to len(p) bytes into p and
+// returns the number of bytes read; if the ring buffer is empty, that
+// is 0.
+func (m *Mirrored) Read(p []byte) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+	first, _ := m.r.Inspect()
+	n := copy(p, m.buf[first.Start:first.Start+m.r.Size()])
+	_, _, err := m.r.ShiftN(uint(n))
+	return n, err
+}
+
+// Close releases the memory mapping backing the ring buffer, if there
+// is one. The ring buffer, and any slices returned by it, must not be
+// used afterwards. Closing a ring buffer more than once does nothing.
+func (m *Mirrored) Close() error {
+	m.Lock()
+	defer m.Unlock()
+	if m.unmap == nil {
+		return nil
+	}
+	err := m.unmap()
+	m.unmap, m.buf = nil, nil
+	return err
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package ringio
+
+import (
+	"math"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapMirrored maps a memfd of the given size (which must be a
+// multiple of the page size) twice into a contiguous region of
+// memory, returning that region and a function that unmaps it.
+func mapMirrored(size int) ([]byte, func() error, error) {
+	if size <= 0 || size > math.MaxInt/2 {
+		// Both halves together must fit into an int.
+		return nil, nil, unix.EINVAL
+	}
+	fd, err := unix.MemfdCreate("ringio.Mirrored", unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The mappings keep the memory alive without the fd:
+	defer unix.Close(fd)
+	if err := unix.Ftruncate(fd, int64(size)); err != nil {
+		return nil, nil, err
+	}
+
+	// Reserve address space for both mappings, then map the memfd
+	// over each half of it.
+	length := uintptr(2 * size)
+	base, err := unix.MmapPtr(-1, 0, nil, length, unix.PROT_NONE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, half := range []unsafe.Pointer{base, unsafe.Add(base, size)} {
+		_, err := unix.MmapPtr(fd, 0, half, uintptr(size), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_FIXED)
+		if err != nil {
+			_ = unix.MunmapPtr(base, length)
+			return nil, nil, err
+		}
+	}
+	unmap := func() error {
+		return unix.MunmapPtr(base, length)
+	}
+	return unsafe.Slice((*byte)(base), 2*size), unmap, nil
+}
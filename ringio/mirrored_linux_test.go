@@ -0,0 +1,31 @@
+//go:build linux
+
+package ringio
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapMirroredFailures(t *testing.T) {
+	t.Parallel()
+	page := os.Getpagesize()
+	for _, test := range []struct {
+		name string
+		size int
+	}{
+		{"negative size", -page},
+		{"zero size", 0},
+		{"unaligned second half", page + 1},
+		{"doubled size overflows", math.MaxInt/2 + 1},
+		{"size overflows", math.MaxInt},
+	} {
+		buf, unmap, err := mapMirrored(test.size)
+		assert.Error(t, err, test.name)
+		assert.Nil(t, buf, test.name)
+		assert.Nil(t, unmap, test.name)
+	}
+}
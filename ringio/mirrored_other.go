@@ -0,0 +1,11 @@
+//go:build !linux
+
+package ringio
+
+import "errors"
+
+// mapMirrored is not supported on this platform; Mirrored falls back
+// to keeping a copy of its buffer.
+func mapMirrored(size int) ([]byte, func() error, error) {
+	return nil, nil, errors.New("mirrored memory mappings are not supported on this platform")
+}
@@ -0,0 +1,110 @@
+package ringio
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrored(t *testing.T) {
+	t.Parallel()
+	page := uint(os.Getpagesize())
+	for _, tryMap := range []bool{true, false} {
+		mapper := mapMirrored
+		if !tryMap {
+			mapper = nil
+		}
+		m := newMirrored(page-10, mapper)
+		if tryMap && runtime.GOOS == "linux" {
+			assert.True(t, m.Mapped())
+		}
+		assert.Equal(t, page, m.Capacity(), "capacity is rounded up to the page size")
+		assert.Equal(t, uint(0), m.Size())
+
+		// Move the read and write ends close to the end of the
+		// buffer:
+		require.NoError(t, m.Commit(int(page-3)))
+		require.NoError(t, m.Release(int(page-3)))
+
+		n, err := m.Write([]byte("hello, world"))
+		require.NoError(t, err)
+		assert.Equal(t, 12, n)
+		assert.Equal(t, "hello, world", string(m.ReadableSlice()), "mapped=%v", tryMap)
+
+		w := m.WritableSlice()
+		assert.Len(t, w, int(page-12))
+		copy(w, "!!")
+		require.NoError(t, m.Commit(2))
+		assert.Equal(t, "hello, world!!", string(m.ReadableSlice()), "mapped=%v", tryMap)
+		assert.Equal(t, uint(14), m.Size())
+
+		require.NoError(t, m.Release(7))
+		read := make([]byte, 5)
+		n, err = m.Read(read)
+		require.NoError(t, err)
+		assert.Equal(t, "world", string(read[:n]))
+
+		assert.Equal(t, o.ErrEmpty, m.Release(3))
+		assert.Equal(t, o.ErrFull, m.Commit(int(page)))
+		_, err = m.Write(make([]byte, page))
+		assert.Equal(t, o.ErrFull, err)
+
+		// Fill the buffer up entirely, wrapping around:
+		w = m.WritableSlice()
+		for i := range w {
+			w[i] = byte(i)
+		}
+		require.NoError(t, m.Commit(len(w)))
+		r := m.ReadableSlice()
+		assert.Equal(t, "!!", string(r[:2]))
+		for i, b := range r[2:] {
+			require.Equal(t, byte(i), b, "mapped=%v, at %d", tryMap, i)
+		}
+		assert.Empty(t, m.WritableSlice())
+		assert.Equal(t, page, m.Size())
+
+		require.NoError(t, m.Close())
+		require.NoError(t, m.Close())
+	}
+}
+
+func TestMirroredZero(t *testing.T) {
+	t.Parallel()
+	m := NewMirrored(0)
+	assert.Equal(t, uint(0), m.Capacity())
+	assert.False(t, m.Mapped())
+	assert.Empty(t, m.ReadableSlice())
+	assert.Empty(t, m.WritableSlice())
+	_, err := m.Write([]byte("x"))
+	assert.Equal(t, o.ErrFull, err)
+}
+
+func TestMirroredFallback(t *testing.T) {
+	t.Parallel()
+	failing := func(size int) ([]byte, func() error, error) {
+		return nil, nil, errors.New("no mapping today")
+	}
+	m := newMirrored(1, failing)
+	assert.False(t, m.Mapped(), "falls back to copying")
+	page := int(m.Capacity())
+
+	w := m.WritableSlice()
+	require.Len(t, w, page)
+	copy(w[page-2:], "ab")
+	require.NoError(t, m.Release(0))
+	require.NoError(t, m.Commit(page))
+	require.NoError(t, m.Release(page-2))
+	_, err := m.Write([]byte("cd"))
+	require.NoError(t, err)
+	n, err := m.Write(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, "abcd", string(m.ReadableSlice()), "second copy mirrors the wrapped bytes")
+	assert.Equal(t, uint(4), m.Size())
+	require.NoError(t, m.Close())
+}
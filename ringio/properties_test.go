@@ -125,3 +125,75 @@ func TestPropReadWritesBounded(t *testing.T) {
 	)
 	properties.TestingRun(t)
 }
+
+func TestPropOverwriteInterleaved(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("overwriting writes drop exactly the oldest bytes", prop.ForAll(
+		func(cap uint, ops []uint) string {
+			b := ringio.New(cap, true)
+			model := []byte{}
+			var label byte
+			for i, op := range ops {
+				// Ops write with ForceWrite or Write, or read:
+				n := int(op / 3)
+				if op%3 < 2 {
+					input := make([]byte, n)
+					for j := range input {
+						label++
+						input[j] = label
+					}
+					free := int(cap) - len(model)
+					wantDropped := 0
+					if n > free {
+						wantDropped = n - free
+					}
+					if op%3 == 0 {
+						dropped, err := b.ForceWrite(input)
+						if err != nil {
+							return fmt.Sprintf("op %d: force-writing %d: %v", i, n, err)
+						}
+						if dropped != wantDropped {
+							return fmt.Sprintf("op %d: dropped %d writing %d, expected %d", i, dropped, n, wantDropped)
+						}
+					} else {
+						written, err := b.Write(input)
+						if err != nil {
+							return fmt.Sprintf("op %d: writing %d: %v", i, n, err)
+						}
+						if written != n {
+							return fmt.Sprintf("op %d: wrote %d, expected %d", i, written, n)
+						}
+					}
+					model = append(model, input...)
+					if len(model) > int(cap) {
+						model = model[len(model)-int(cap):]
+					}
+				} else {
+					output := make([]byte, n)
+					read, err := b.Read(output)
+					if err != nil {
+						return fmt.Sprintf("op %d: reading %d: %v", i, n, err)
+					}
+					want := n
+					if want > len(model) {
+						want = len(model)
+					}
+					if !reflect.DeepEqual(output[:read], model[:want]) {
+						return fmt.Sprintf("op %d: read %v, expected %v", i, output[:read], model[:want])
+					}
+					model = model[want:]
+				}
+				first, second := b.Peek(int(cap))
+				if got := append(append([]byte{}, first...), second...); !reflect.DeepEqual(got, model) {
+					return fmt.Sprintf("op %d: buffer holds %v, expected %v", i, got, model)
+				}
+			}
+			return ""
+		},
+		gen.UIntRange(0, 16).WithLabel("buffer size"),
+		gen.SliceOf(gen.UIntRange(0, 3*40)).WithLabel("operations"),
+	))
+	properties.TestingRun(t)
+}
@@ -0,0 +1,155 @@
+package ringio
+
+import (
+	"bufio"
+	"context"
+	"unicode/utf8"
+
+	"github.com/antifuchs/o"
+)
+
+// awaitReadable returns nil once at least one byte is readable on
+// the ring buffer. If none is, it waits on a ring buffer created with
+// NewBlocking, and otherwise returns the error that a read of a
+// single byte or rune fails with: the error the ring buffer was closed
+// with, ErrWouldBlock for one created with NewNonBlocking, or
+// o.ErrEmpty. It must be called with the lock held.
+func (b *Bounded) awaitReadable() error {
+	// Rings of capacity 0 are never Empty.
+	for b.r.Size() == 0 {
+		switch {
+		case b.closeErr != nil:
+			return b.closeErr
+		case b.blocking:
+			_ = b.wait(context.Background())
+			b.Lock()
+		case b.nonBlocking:
+			b.failedShift()
+			return ErrWouldBlock
+		default:
+			b.failedShift()
+			return o.ErrEmpty
+		}
+	}
+	return nil
+}
+
+// ReadByte implements io.ByteReader. If no byte is readable, it fails
+// like ReadRune.
+func (b *Bounded) ReadByte() (byte, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if err := b.awaitReadable(); err != nil {
+		return 0, err
+	}
+	idx, err := b.r.Shift()
+	if err != nil {
+		return 0, err
+	}
+	c := b.buf[idx]
+	b.signal()
+	b.last[0], b.lastN = c, 1
+	return c, nil
+}
+
+// UnreadByte implements io.ByteScanner: It puts the last byte
+// returned by ReadByte (or the last byte of the rune returned by
+// ReadRune) back at the read end of the ring buffer. It fails with
+// bufio.ErrInvalidUnreadByte unless the ring buffer was not changed
+// since that call.
+func (b *Bounded) UnreadByte() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.lastN == 0 {
+		return bufio.ErrInvalidUnreadByte
+	}
+	return b.unread(b.last[b.lastN-1 : b.lastN])
+}
+
+// ReadRune implements io.RuneReader, decoding the UTF-8 encoded rune
+// at the read end of the ring buffer, even if its bytes wrap around
+// the end of the backing buffer.
+//
+// If only the beginning of a multi-byte rune is readable, ReadRune
+// waits for the rest on a ring buffer created with NewBlocking, and
+// otherwise fails with ErrWouldBlock (on one created with
+// NewNonBlocking) or o.ErrEmpty. If no byte is readable at all,
+// ReadRune returns the error that the ring buffer was closed with,
+// or fails the same way. Once the ring buffer is closed or full, an
+// incomplete rune is decoded as utf8.RuneError of size 1.
+func (b *Bounded) ReadRune() (r rune, size int, err error) {
+	b.Lock()
+	defer b.Unlock()
+
+	var enc [utf8.UTFMax]byte
+	for {
+		if err = b.awaitReadable(); err != nil {
+			return 0, 0, err
+		}
+		first, second := b.r.Inspect()
+		n := copy(enc[:], b.buf[first.Start:first.End])
+		n += copy(enc[n:], b.buf[second.Start:second.End])
+		if utf8.FullRune(enc[:n]) || b.closeErr != nil || b.r.Full() {
+			r, size = utf8.DecodeRune(enc[:n])
+			break
+		}
+		switch {
+		case b.blocking:
+			_ = b.wait(context.Background())
+			b.Lock()
+		case b.nonBlocking:
+			b.failedShift()
+			return 0, 0, ErrWouldBlock
+		default:
+			b.failedShift()
+			return 0, 0, o.ErrEmpty
+		}
+	}
+
+	if _, _, err = b.r.ShiftN(uint(size)); err != nil {
+		return 0, 0, err
+	}
+	b.signal()
+	b.last, b.lastN, b.lastRune = enc, size, true
+	return r, size, nil
+}
+
+// UnreadRune implements io.RuneScanner: It puts the bytes of the rune
+// returned by the last ReadRune back at the read end of the ring
+// buffer. It fails with bufio.ErrInvalidUnreadRune unless the last
+// call was to ReadRune and the ring buffer was not changed since.
+func (b *Bounded) UnreadRune() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if !b.lastRune {
+		return bufio.ErrInvalidUnreadRune
+	}
+	return b.unread(b.last[:b.lastN])
+}
+
+// unread puts p back at the read end of the ring buffer.
+func (b *Bounded) unread(p []byte) error {
+	first, second, err := b.r.UnshiftN(uint(len(p)))
+	if err != nil {
+		return err
+	}
+	n := copy(b.buf[first.Start:first.End], p)
+	copy(b.buf[second.Start:second.End], p[n:])
+	b.signal()
+	return nil
+}
+
+// WriteByte implements io.ByteWriter. It writes c like Write does.
+func (b *Bounded) WriteByte(c byte) error {
+	_, err := b.Write([]byte{c})
+	return err
+}
+
+// WriteString implements io.StringWriter. It writes s like Write
+// does.
+func (b *Bounded) WriteString(s string) (n int, err error) {
+	return b.Write([]byte(s))
+}
@@ -0,0 +1,173 @@
+package ringio
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ io.ByteScanner  = &Bounded{}
+	_ io.RuneScanner  = &Bounded{}
+	_ io.ByteWriter   = &Bounded{}
+	_ io.StringWriter = &Bounded{}
+)
+
+func TestReadUnreadByte(t *testing.T) {
+	t.Parallel()
+	b := New(3, false)
+	_, err := b.ReadByte()
+	assert.Equal(t, o.ErrEmpty, err)
+	assert.Equal(t, bufio.ErrInvalidUnreadByte, b.UnreadByte())
+
+	require.NoError(t, b.WriteByte('a'))
+	_, err = b.WriteString("bc")
+	require.NoError(t, err)
+	c, err := b.ReadByte()
+	require.NoError(t, err)
+	assert.Equal(t, byte('a'), c)
+	require.NoError(t, b.UnreadByte())
+	assert.Equal(t, bufio.ErrInvalidUnreadByte, b.UnreadByte(), "only one byte can be unread")
+
+	for _, want := range []byte("abc") {
+		c, err = b.ReadByte()
+		require.NoError(t, err)
+		assert.Equal(t, want, c)
+	}
+
+	// The read end is at index 0 now; unreading moves it back
+	// across the end of the backing buffer.
+	require.NoError(t, b.UnreadByte())
+	c, err = b.ReadByte()
+	require.NoError(t, err)
+	assert.Equal(t, byte('c'), c)
+}
+
+func TestUnreadAfterWrite(t *testing.T) {
+	t.Parallel()
+	b := New(4, false)
+	_, err := b.WriteString("ab")
+	require.NoError(t, err)
+	_, err = b.ReadByte()
+	require.NoError(t, err)
+	require.NoError(t, b.WriteByte('c'))
+	assert.Equal(t, bufio.ErrInvalidUnreadByte, b.UnreadByte())
+}
+
+func TestReadRuneWrapped(t *testing.T) {
+	t.Parallel()
+	for offset := 0; offset < 6; offset++ {
+		b := New(6, false)
+		_, err := b.Write(make([]byte, offset))
+		require.NoError(t, err)
+		_, err = b.Read(make([]byte, offset))
+		require.NoError(t, err)
+
+		// Every rune straddles the wrap point at some offset:
+		_, err = b.WriteString("é€")
+		require.NoError(t, err)
+		r, size, err := b.ReadRune()
+		require.NoError(t, err, "offset %d", offset)
+		assert.Equal(t, 'é', r, "offset %d", offset)
+		assert.Equal(t, 2, size)
+		r, size, err = b.ReadRune()
+		require.NoError(t, err, "offset %d", offset)
+		assert.Equal(t, '€', r, "offset %d", offset)
+		assert.Equal(t, 3, size)
+
+		require.NoError(t, b.UnreadRune())
+		assert.Equal(t, bufio.ErrInvalidUnreadRune, b.UnreadRune())
+		r, _, err = b.ReadRune()
+		require.NoError(t, err)
+		assert.Equal(t, '€', r, "offset %d", offset)
+		_, _, err = b.ReadRune()
+		assert.Equal(t, o.ErrEmpty, err)
+	}
+}
+
+func TestReadRuneIncomplete(t *testing.T) {
+	t.Parallel()
+	euro := []byte("€")
+
+	b := NewNonBlocking(8, false)
+	_, err := b.Write(euro[:2])
+	require.NoError(t, err)
+	_, _, err = b.ReadRune()
+	assert.Equal(t, ErrWouldBlock, err)
+	_, err = b.Write(euro[2:])
+	require.NoError(t, err)
+	r, _, err := b.ReadRune()
+	require.NoError(t, err)
+	assert.Equal(t, '€', r)
+
+	_, err = b.Write(euro[:1])
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+	r, size, err := b.ReadRune()
+	require.NoError(t, err)
+	assert.Equal(t, utf8.RuneError, r, "closed with an incomplete rune")
+	assert.Equal(t, 1, size)
+	_, _, err = b.ReadRune()
+	assert.Equal(t, io.EOF, err)
+
+	blocking := NewBlocking(8, false)
+	_, err = blocking.Write(euro[:1])
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = blocking.Write(euro[1:])
+	}()
+	r, _, err = blocking.ReadRune()
+	require.NoError(t, err)
+	assert.Equal(t, '€', r, "waited for the rest of the rune")
+}
+
+func TestReadRuneZeroCapacity(t *testing.T) {
+	t.Parallel()
+	b := New(0, false)
+	_, _, err := b.ReadRune()
+	assert.Equal(t, o.ErrEmpty, err)
+	_, err = b.ReadByte()
+	assert.Equal(t, o.ErrEmpty, err)
+
+	nb := NewNonBlocking(0, false)
+	_, _, err = nb.ReadRune()
+	assert.Equal(t, ErrWouldBlock, err)
+	_, err = nb.ReadByte()
+	assert.Equal(t, ErrWouldBlock, err)
+	require.NoError(t, nb.Close())
+	_, _, err = nb.ReadRune()
+	assert.Equal(t, io.EOF, err)
+	_, err = nb.ReadByte()
+	assert.Equal(t, io.EOF, err)
+
+	blocking := NewBlocking(0, false)
+	done := make(chan error)
+	go func() {
+		_, _, err := blocking.ReadRune()
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, blocking.Close())
+	assert.Equal(t, io.EOF, <-done, "waited until closed")
+}
+
+func TestRuneScanning(t *testing.T) {
+	t.Parallel()
+	b := New(16, false)
+	_, err := b.WriteString("héllo wörld\n")
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+
+	scanner := bufio.NewScanner(b)
+	require.True(t, scanner.Scan())
+	assert.Equal(t, "héllo wörld", scanner.Text())
+	assert.False(t, scanner.Scan())
+	assert.NoError(t, scanner.Err())
+}
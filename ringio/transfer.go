@@ -0,0 +1,160 @@
+package ringio
+
+import (
+	"context"
+	"io"
+
+	"github.com/antifuchs/o"
+)
+
+// ReadFrom implements io.ReaderFrom: It reads from r directly into
+// the free space of the ring buffer until r returns io.EOF or an
+// error, without an intermediate buffer. io.Copy uses it when copying
+// into a Bounded.
+//
+// While reading from r, ReadFrom holds a reservation on the free
+// space (see o.Ring.Reserve), but not the ring buffer's lock, so
+// readers can drain the ring buffer concurrently. Writes that happen
+// in the meantime fail with o.ErrReserved and write nothing, even on a
+// ring buffer that overwrites; a concurrent ReadFrom fails the same
+// way.
+//
+// Once the ring buffer is full, ReadFrom waits for room on a ring
+// buffer created with NewBlocking. On one that overwrites, it reads
+// into a scratch buffer instead and drops the oldest bytes like
+// ForceWrite. Otherwise, it returns o.ErrFull (or ErrWouldBlock, if
+// the ring buffer was created with NewNonBlocking). Like WriteContext,
+// ReadFrom fails with io.ErrShortWrite instead of waiting on a
+// blocking ring buffer of capacity 0.
+func (b *Bounded) ReadFrom(r io.Reader) (n int64, err error) {
+	b.Lock()
+	if b.filling {
+		b.Unlock()
+		return 0, o.ErrReserved
+	}
+	b.filling = true
+	b.Unlock()
+	defer func() {
+		b.Lock()
+		b.filling = false
+		b.Unlock()
+	}()
+
+	var scratch []byte
+	for {
+		b.Lock()
+		if b.closeErr != nil {
+			b.Unlock()
+			return n, io.ErrClosedPipe
+		}
+		free := b.r.Capacity() - b.r.Size()
+		if free == 0 && !b.overwrite {
+			switch {
+			case b.blocking && b.r.Capacity() == 0:
+				b.failedPush()
+				b.Unlock()
+				return n, io.ErrShortWrite
+			case b.blocking:
+				if err = b.wait(context.Background()); err != nil {
+					return n, err
+				}
+				continue
+			case b.nonBlocking && b.r.Capacity() > 0:
+				b.failedPush()
+				b.Unlock()
+				return n, ErrWouldBlock
+			default:
+				b.failedPush()
+				b.Unlock()
+				return n, o.ErrFull
+			}
+		}
+
+		var m int
+		var rerr error
+		if free == 0 {
+			b.Unlock()
+			if scratch == nil {
+				scratch = make([]byte, b.r.Capacity()+1)
+			}
+			m, rerr = r.Read(scratch)
+			b.Lock()
+			if _, err = b.forceWrite(scratch[:m]); err != nil {
+				b.Unlock()
+				return n, err
+			}
+			b.Unlock()
+		} else {
+			first, _, _ := b.r.Reserve(free)
+			b.reserving = true
+			dst := b.buf[first.Start:first.End]
+			b.Unlock()
+			m, rerr = r.Read(dst)
+			b.Lock()
+			b.r.Commit(uint(m))
+			b.reserving = false
+			b.signal()
+			b.Unlock()
+		}
+		n += int64(m)
+		if rerr == io.EOF {
+			return n, nil
+		}
+		if rerr != nil {
+			return n, rerr
+		}
+	}
+}
+
+// WriteTo implements io.WriterTo: It writes the readable bytes of the
+// ring buffer directly to w, without an intermediate buffer, and
+// consumes the bytes that w accepted. io.Copy uses it when copying
+// from a Bounded.
+//
+// WriteTo holds the ring buffer's lock while writing to w. It returns
+// once the ring buffer is empty, or, on a ring buffer created with
+// NewBlocking, once the ring buffer is closed and drained. If the ring
+// buffer was closed with an error other than io.EOF, WriteTo returns
+// that error once drained.
+func (b *Bounded) WriteTo(w io.Writer) (n int64, err error) {
+	b.Lock()
+	for {
+		if b.r.Size() == 0 {
+			// Rings of capacity 0 are never Empty.
+			if b.blocking && b.closeErr == nil {
+				if err = b.wait(context.Background()); err != nil {
+					return n, err
+				}
+				b.Lock()
+				continue
+			}
+			closeErr := b.closeErr
+			b.Unlock()
+			if closeErr != nil && closeErr != io.EOF {
+				return n, closeErr
+			}
+			return n, nil
+		}
+
+		// Shift before writing, so that a failed write can hand
+		// back exactly the bytes that w did not accept.
+		first, _ := b.r.Inspect()
+		if _, _, err = b.r.ShiftN(first.Length()); err != nil {
+			b.Unlock()
+			return n, err
+		}
+		m, werr := w.Write(b.buf[first.Start:first.End])
+		if m < int(first.Length()) {
+			_, _, _ = b.r.UnshiftN(first.Length() - uint(m))
+			if werr == nil {
+				werr = io.ErrShortWrite
+			}
+		}
+		n += int64(m)
+		b.signal()
+		if werr != nil {
+			b.Unlock()
+			return n, werr
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package ringio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onlyReader hides all methods of a reader but Read, so io.Copy has
+// to use the destination's ReadFrom.
+type onlyReader struct{ io.Reader }
+
+// shortWriter accepts at most limit bytes per call.
+type shortWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		p = p[:w.limit]
+	}
+	return w.Buffer.Write(p)
+}
+
+// drain reads all readable bytes from b.
+func drain(b *Bounded) string {
+	buf := make([]byte, b.r.Capacity())
+	n, _ := b.Read(buf)
+	return string(buf[:n])
+}
+
+func TestReadFrom(t *testing.T) {
+	t.Parallel()
+	b := New(8, false)
+	_, err := b.Write([]byte("abcde"))
+	require.NoError(t, err)
+	_, err = b.Read(make([]byte, 4))
+	require.NoError(t, err)
+
+	n, err := io.Copy(b, onlyReader{strings.NewReader("fghij")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	assert.Equal(t, "efghij", drain(b), "wraps around")
+
+	n, err = b.ReadFrom(strings.NewReader("0123456789"))
+	assert.Equal(t, o.ErrFull, err)
+	assert.Equal(t, int64(8), n)
+	assert.Equal(t, "01234567", drain(b))
+
+	nb := NewNonBlocking(2, false)
+	_, err = nb.ReadFrom(strings.NewReader("abc"))
+	assert.Equal(t, ErrWouldBlock, err)
+}
+
+func TestReadFromOverwrite(t *testing.T) {
+	t.Parallel()
+	b := New(4, true)
+	n, err := b.ReadFrom(strings.NewReader("abcdefghij"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+	assert.Equal(t, "ghij", drain(b))
+}
+
+func TestWriteTo(t *testing.T) {
+	t.Parallel()
+	b := New(8, false)
+	_, err := b.Write([]byte("abcdef"))
+	require.NoError(t, err)
+	_, err = b.Read(make([]byte, 4))
+	require.NoError(t, err)
+	_, err = b.Write([]byte("ghijkl"))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	n, err := io.Copy(&out, b)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), n)
+	assert.Equal(t, "efghijkl", out.String())
+
+	_, err = b.Write([]byte("mnop"))
+	require.NoError(t, err)
+	short := &shortWriter{limit: 3}
+	n, err = b.WriteTo(short)
+	assert.Equal(t, io.ErrShortWrite, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, "mno", short.String())
+	assert.Equal(t, "p", drain(b), "unwritten bytes stay on the ring buffer")
+
+	failure := errors.New("boom")
+	_, err = b.Write([]byte("q"))
+	require.NoError(t, err)
+	require.NoError(t, b.CloseWithError(failure))
+	out.Reset()
+	n, err = b.WriteTo(&out)
+	assert.Equal(t, failure, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestWriteToZeroCapacity(t *testing.T) {
+	t.Parallel()
+	for _, b := range []*Bounded{New(0, false), New(0, true), NewNonBlocking(0, false)} {
+		var out bytes.Buffer
+		n, err := b.WriteTo(&out)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+	}
+
+	b := NewBlocking(0, false)
+	done := make(chan error)
+	go func() {
+		_, err := b.WriteTo(&bytes.Buffer{})
+		done <- err
+	}()
+	require.NoError(t, b.Close())
+	assert.NoError(t, <-done, "WriteTo returns once the ring buffer is closed")
+}
+
+func TestReadFromZeroCapacity(t *testing.T) {
+	t.Parallel()
+	n, err := NewBlocking(0, false).ReadFrom(strings.NewReader("abc"))
+	assert.Equal(t, io.ErrShortWrite, err, "must not wait forever")
+	assert.Equal(t, int64(0), n)
+	_, err = NewNonBlocking(0, false).ReadFrom(strings.NewReader("abc"))
+	assert.Equal(t, o.ErrFull, err, "no read can ever fit")
+
+	n, err = NewBlocking(0, true).ReadFrom(strings.NewReader("abc"))
+	require.NoError(t, err, "overwriting drops all bytes")
+	assert.Equal(t, int64(3), n)
+}
+
+func TestBlockingCopy(t *testing.T) {
+	t.Parallel()
+	b := NewBlocking(7, false)
+	input := bytes.Repeat([]byte("0123456789"), 50)
+	go func() {
+		_, err := io.Copy(b, onlyReader{bytes.NewReader(input)})
+		if err != nil {
+			panic(err)
+		}
+		_ = b.Close()
+	}()
+
+	var out bytes.Buffer
+	n, err := io.Copy(&out, b)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(input)), n)
+	assert.Equal(t, input, out.Bytes())
+}
+
+// gatedReader blocks in Read until a value is sent on its gate.
+type gatedReader struct {
+	reading chan struct{}
+	gate    chan []byte
+}
+
+func (r *gatedReader) Read(p []byte) (int, error) {
+	r.reading <- struct{}{}
+	data, ok := <-r.gate
+	if !ok {
+		return 0, io.EOF
+	}
+	return copy(p, data), nil
+}
+
+func TestWriteDuringReadFrom(t *testing.T) {
+	t.Parallel()
+	for _, overwrite := range []bool{false, true} {
+		b := New(8, overwrite)
+		_, err := b.Write([]byte("abc"))
+		require.NoError(t, err)
+
+		r := &gatedReader{reading: make(chan struct{}), gate: make(chan []byte)}
+		done := make(chan error)
+		go func() {
+			_, err := b.ReadFrom(r)
+			done <- err
+		}()
+		<-r.reading
+
+		n, err := b.Write([]byte("defgh"))
+		assert.Equal(t, o.ErrReserved, err, "overwrite=%t", overwrite)
+		assert.Equal(t, 0, n, "overwrite=%t", overwrite)
+		if overwrite {
+			// Would have to drop bytes to make room.
+			_, err = b.Write([]byte("0123456789"))
+			assert.Equal(t, o.ErrReserved, err)
+		}
+		_, err = b.ForceWrite([]byte("xyz"))
+		assert.Equal(t, o.ErrReserved, err, "overwrite=%t", overwrite)
+		_, err = b.ReadFrom(strings.NewReader("z"))
+		assert.Equal(t, o.ErrReserved, err, "overwrite=%t", overwrite)
+		assert.Equal(t, "abc", string(b.Snapshot()), "overwrite=%t: nothing dropped", overwrite)
+
+		r.gate <- []byte("ij")
+		<-r.reading
+		close(r.gate)
+		require.NoError(t, <-done)
+		assert.Equal(t, "abcij", drain(b), "overwrite=%t", overwrite)
+	}
+}
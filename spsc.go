@@ -0,0 +1,141 @@
+package o
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// cacheLine is the assumed size of a CPU cache line, used to keep
+// the producer's and the consumer's state from sharing one.
+const cacheLine = 64
+
+// SPSCRing provides ring buffer accounting that is safe for use by
+// exactly one producer and one consumer goroutine at the same time,
+// without a lock.
+//
+// The producer calls Reserve to get the free indexes it may fill,
+// and Publish to make the filled elements visible to the consumer.
+// The consumer calls Peek to get the occupied indexes it may read,
+// and Release to hand them back to the producer. Publish and Release
+// synchronize with each other, so writes to the backing buffer that
+// happen before a Publish are visible to the consumer after its
+// Peek, and reads that happen before a Release are complete before
+// the producer can Reserve those indexes again.
+//
+// Like maskRing, SPSCRing only supports power-of-two capacities.
+type SPSCRing struct {
+	_ [cacheLine]byte
+
+	// Producer-owned state:
+	write    atomic.Uint64
+	reserved uint
+	_        [cacheLine - 16]byte
+
+	// Consumer-owned state:
+	read   atomic.Uint64
+	peeked uint
+	_      [cacheLine - 16]byte
+
+	cap uint
+}
+
+// NewSPSCRing returns an SPSCRing with the given capacity, which must
+// be a power of two. Otherwise, it returns ErrCapacity.
+func NewSPSCRing(cap uint) (*SPSCRing, error) {
+	if bits.OnesCount(cap) != 1 {
+		return nil, ErrCapacity
+	}
+	return &SPSCRing{cap: cap}, nil
+}
+
+func (r *SPSCRing) mask(val uint64) uint {
+	return uint(val) & (r.cap - 1)
+}
+
+// ranges returns the Ranges covering count indexes starting at the
+// (unmasked) counter value at.
+func (r *SPSCRing) ranges(at uint64, count uint) (first, second Range) {
+	if count == 0 {
+		return
+	}
+	return split(r.mask(at), r.mask(at+uint64(count)), r.cap)
+}
+
+// Capacity returns the number of continuous indexes that can be
+// represented on the ring.
+func (r *SPSCRing) Capacity() uint {
+	return r.cap
+}
+
+// Size returns the number of published elements that have not been
+// released yet. If called concurrently with Publish or Release, the
+// result may be out of date by the time it is returned.
+func (r *SPSCRing) Size() uint {
+	read := r.read.Load()
+	return uint(r.write.Load() - read)
+}
+
+// Reserve returns ranges covering the next count free indexes on the
+// ring. The producer may fill them, and then make (some of) them
+// available to the consumer with Publish. Calling Reserve again
+// replaces the previous reservation.
+//
+// If fewer than count indexes are free, Reserve reserves nothing and
+// returns ErrFull.
+//
+// Reserve must only be called from the producer goroutine.
+func (r *SPSCRing) Reserve(count uint) (first, second Range, err error) {
+	write := r.write.Load()
+	if count > r.cap-uint(write-r.read.Load()) {
+		r.reserved = 0
+		return first, second, ErrFull
+	}
+	r.reserved = count
+	first, second = r.ranges(write, count)
+	return
+}
+
+// Publish makes the first count reserved indexes readable by the
+// consumer and ends the reservation. Publishing more indexes than
+// were reserved panics.
+//
+// Publish must only be called from the producer goroutine.
+func (r *SPSCRing) Publish(count uint) {
+	if count > r.reserved {
+		panic("Publish called with more indexes than were reserved.")
+	}
+	r.reserved = 0
+	r.write.Add(uint64(count))
+}
+
+// Peek returns ranges covering the count oldest published indexes on
+// the ring, without removing them. The consumer may read them, and
+// then hand (some of) them back to the producer with Release.
+// Calling Peek again replaces the previous peek.
+//
+// If fewer than count indexes are readable, Peek returns ErrEmpty.
+//
+// Peek must only be called from the consumer goroutine.
+func (r *SPSCRing) Peek(count uint) (first, second Range, err error) {
+	read := r.read.Load()
+	if count > uint(r.write.Load()-read) {
+		r.peeked = 0
+		return first, second, ErrEmpty
+	}
+	r.peeked = count
+	first, second = r.ranges(read, count)
+	return
+}
+
+// Release frees the first count peeked indexes for reuse by the
+// producer and ends the peek. Releasing more indexes than were
+// peeked panics.
+//
+// Release must only be called from the consumer goroutine.
+func (r *SPSCRing) Release(count uint) {
+	if count > r.peeked {
+		panic("Release called with more indexes than were peeked.")
+	}
+	r.peeked = 0
+	r.read.Add(uint64(count))
+}
@@ -0,0 +1,59 @@
+package o_test
+
+import (
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSPSCCapacity(t *testing.T) {
+	t.Parallel()
+	_, err := o.NewSPSCRing(0)
+	assert.Equal(t, o.ErrCapacity, err)
+	_, err = o.NewSPSCRing(12)
+	assert.Equal(t, o.ErrCapacity, err)
+
+	r, err := o.NewSPSCRing(8)
+	require.NoError(t, err)
+	assert.Equal(t, uint(8), r.Capacity())
+}
+
+func TestSPSCReservePublish(t *testing.T) {
+	t.Parallel()
+	r, err := o.NewSPSCRing(4)
+	require.NoError(t, err)
+
+	_, _, err = r.Peek(1)
+	assert.Equal(t, o.ErrEmpty, err)
+
+	first, second, err := r.Reserve(3)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 0, End: 3}, first)
+	assert.True(t, second.Empty())
+	assert.Equal(t, uint(0), r.Size(), "reserved indexes are not readable")
+
+	r.Publish(2)
+	assert.Equal(t, uint(2), r.Size())
+	assert.Panics(t, func() { r.Publish(1) }, "reservation ended")
+
+	first, _, err = r.Peek(2)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 0, End: 2}, first)
+	r.Release(2)
+	assert.Panics(t, func() { r.Release(1) }, "peek ended")
+
+	_, _, err = r.Reserve(5)
+	assert.Equal(t, o.ErrFull, err)
+	first, second, err = r.Reserve(4)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 2, End: 4}, first)
+	assert.Equal(t, o.Range{Start: 0, End: 2}, second)
+	r.Publish(4)
+
+	first, second, err = r.Peek(4)
+	require.NoError(t, err)
+	assert.Equal(t, o.Range{Start: 2, End: 4}, first)
+	assert.Equal(t, o.Range{Start: 0, End: 2}, second)
+}
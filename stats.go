@@ -0,0 +1,110 @@
+package o
+
+// Stats holds counters of the operations performed on a ring since
+// its statistics were enabled or last reset. See Ring.EnableStats.
+type Stats struct {
+	// Pushed counts the elements added to the ring, at either end.
+	Pushed uint64
+
+	// Shifted counts the elements removed from the ring, at either
+	// end, including those discarded by Consume.
+	Shifted uint64
+
+	// Overwritten counts the elements that ForcePush discarded to
+	// make room for new ones. They are not counted as Shifted.
+	Overwritten uint64
+
+	// FailedPushes counts the additions that failed with ErrFull.
+	FailedPushes uint64
+
+	// FailedShifts counts the removals that failed with ErrEmpty.
+	FailedShifts uint64
+
+	// HighWater is the largest number of elements that were on the
+	// ring at the same time.
+	HighWater uint
+}
+
+// The counting methods below are no-ops on a nil *Stats, so that
+// backends only pay for a nil check while statistics are disabled.
+
+func (s *Stats) pushed(n, size uint) {
+	if s == nil {
+		return
+	}
+	s.Pushed += uint64(n)
+	if size > s.HighWater {
+		s.HighWater = size
+	}
+}
+
+func (s *Stats) shifted(n uint) {
+	if s == nil {
+		return
+	}
+	s.Shifted += uint64(n)
+}
+
+// overwrote turns the most recently counted shift into an overwrite.
+func (s *Stats) overwrote() {
+	if s == nil {
+		return
+	}
+	s.Shifted--
+	s.Overwritten++
+}
+
+func (s *Stats) failedPush(err error) {
+	if s == nil || err != ErrFull {
+		return
+	}
+	s.FailedPushes++
+}
+
+func (s *Stats) failedShift(err error) {
+	if s == nil || err != ErrEmpty {
+		return
+	}
+	s.FailedShifts++
+}
+
+// EnableStats starts counting the operations performed on the ring,
+// which Stats returns. Enabling statistics that are already enabled
+// does nothing.
+//
+// Statistics are shared by all copies of a Ring. Rings of capacity 0
+// do not keep statistics.
+func (r Ring) EnableStats() {
+	enableStats(r.ringBackend)
+}
+
+func enableStats[B ringBackend](b B) {
+	if b.statistics() == nil {
+		b.setStatistics(&Stats{HighWater: b.size()})
+	}
+}
+
+// Stats returns the operation counters of the ring. If statistics are
+// not enabled, all counters are zero.
+func (r Ring) Stats() Stats {
+	return currentStats(r.ringBackend)
+}
+
+func currentStats[B ringBackend](b B) Stats {
+	if s := b.statistics(); s != nil {
+		return *s
+	}
+	return Stats{}
+}
+
+// ResetStats sets all operation counters of the ring back to zero, and
+// the high-water mark to the ring's current Size.
+func (r Ring) ResetStats() {
+	resetStats(r.ringBackend)
+}
+
+func resetStats[B ringBackend](b B) {
+	if s := b.statistics(); s != nil {
+		*s = Stats{HighWater: b.size()}
+	}
+}
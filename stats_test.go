@@ -0,0 +1,135 @@
+package o_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/antifuchs/o"
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+	for _, cap := range []uint{4, 5} {
+		ring := o.NewRing(cap)
+		_, err := ring.Push()
+		require.NoError(t, err)
+		assert.Equal(t, o.Stats{}, ring.Stats(), "disabled by default")
+
+		ring.EnableStats()
+		_, _, err = ring.PushN(cap - 1)
+		require.NoError(t, err)
+		_, err = ring.Push()
+		assert.Equal(t, o.ErrFull, err)
+		ring.ForcePush()
+		ring.ForcePush()
+		_, _, err = ring.ShiftN(cap - 1)
+		require.NoError(t, err)
+		_, err = ring.Pop()
+		require.NoError(t, err)
+		_, err = ring.Shift()
+		assert.Equal(t, o.ErrEmpty, err)
+		assert.Equal(t, o.Stats{
+			Pushed:       uint64(cap + 1),
+			Shifted:      uint64(cap),
+			Overwritten:  2,
+			FailedPushes: 1,
+			FailedShifts: 1,
+			HighWater:    cap,
+		}, ring.Stats(), "capacity %d", cap)
+
+		_, _, err = ring.Reserve(2)
+		require.NoError(t, err)
+		ring.Commit(2)
+		_, _, err = ring.PeekN(1)
+		require.NoError(t, err)
+		ring.Release(1)
+		ring.ResetStats()
+		assert.Equal(t, o.Stats{HighWater: 1}, ring.Stats(), "capacity %d", cap)
+
+		ring.Consume()
+		assert.Equal(t, o.Stats{Shifted: 1, HighWater: 1}, ring.Stats(), "capacity %d", cap)
+	}
+}
+
+func TestStatsResize(t *testing.T) {
+	t.Parallel()
+	ring := o.NewRing(4)
+	ring.EnableStats()
+	_, _, err := ring.PushN(4)
+	require.NoError(t, err)
+	resized, _, err := ring.Resize(3, true)
+	require.NoError(t, err)
+	assert.Equal(t, o.Stats{Pushed: 4, Overwritten: 1, HighWater: 4}, resized.Stats())
+	_, err = resized.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resized.Stats().Shifted)
+	assert.Equal(t, uint64(0), ring.Stats().Shifted, "old ring keeps its own counters")
+}
+
+func TestStatsConcrete(t *testing.T) {
+	t.Parallel()
+	r, err := o.NewMaskRing(2)
+	require.NoError(t, err)
+	r.EnableStats()
+	_, err = r.Push()
+	require.NoError(t, err)
+	_, err = r.Shift()
+	require.NoError(t, err)
+	assert.Equal(t, o.Stats{Pushed: 1, Shifted: 1, HighWater: 1}, r.Stats())
+	r.ResetStats()
+	assert.Equal(t, o.Stats{}, r.Stats())
+
+	zero := o.NewRing(0)
+	zero.EnableStats()
+	_, err = zero.Push()
+	assert.Equal(t, o.ErrFull, err)
+	assert.Equal(t, o.Stats{}, zero.Stats(), "zero rings do not keep statistics")
+}
+
+func TestPropStatsBalance(t *testing.T) {
+	params := gopter.DefaultTestParameters()
+	params.MinSuccessfulTests = 1000
+	properties := gopter.NewProperties(params)
+	properties.Property("counters add up to the ring's size", prop.ForAll(
+		func(cap uint, ops []uint) string {
+			ring := o.NewRing(cap)
+			ring.EnableStats()
+			var highWater uint
+			for i, op := range ops {
+				n := op / 4
+				switch op % 4 {
+				case 0:
+					_, _, _ = ring.PushN(n)
+				case 1:
+					_, _, _ = ring.ShiftN(n)
+				case 2:
+					ring.ForcePush()
+				case 3:
+					_, _, _ = ring.PopN(n)
+				}
+				if err := ring.Check(); err != nil {
+					return fmt.Sprintf("op %d: %v", i, err)
+				}
+				if ring.Size() > highWater {
+					highWater = ring.Size()
+				}
+				s := ring.Stats()
+				if s.Pushed-s.Shifted-s.Overwritten != uint64(ring.Size()) {
+					return fmt.Sprintf("op %d: %+v does not add up to size %d", i, s, ring.Size())
+				}
+				if s.HighWater != highWater {
+					return fmt.Sprintf("op %d: high-water mark %d, expected %d", i, s.HighWater, highWater)
+				}
+			}
+			return ""
+		},
+		gen.UIntRange(1, 20).WithLabel("capacity"),
+		gen.SliceOf(gen.UIntRange(0, 4*8)).WithLabel("operations"),
+	))
+	properties.TestingRun(t)
+}
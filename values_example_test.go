@@ -0,0 +1,27 @@
+package o_test
+
+import (
+	"fmt"
+
+	"github.com/antifuchs/o"
+)
+
+func ExampleValues() {
+	ring := o.NewRing(4)
+	buf := make([]string, 4)
+	for _, word := range []string{"the", "quick", "brown", "fox", "jumps"} {
+		i, _ := ring.ForcePush()
+		buf[i] = word
+	}
+
+	for word := range o.Values(ring, buf) {
+		fmt.Print(word, ",")
+	}
+	fmt.Println()
+	for word := range o.BackwardValues(ring, buf) {
+		fmt.Print(word, ",")
+	}
+	// Output:
+	// quick,brown,fox,jumps,
+	// jumps,fox,brown,quick,
+}
@@ -42,4 +42,54 @@ func (z zeroRing) pushN(_ uint) (start uint, end uint, err error) {
 	return 0, 0, ErrFull
 }
 
+func (z zeroRing) unshiftN(_ uint) (start uint, end uint, err error) {
+	return 0, 0, ErrFull
+}
+
+func (z zeroRing) popN(uint) (uint, uint, error) {
+	return 0, 0, ErrEmpty
+}
+
+func (z zeroRing) reserveN(n uint) (uint, uint, error) {
+	if n > 0 {
+		return 0, 0, ErrFull
+	}
+	return 0, 0, nil
+}
+
+func (z zeroRing) commit(n uint) {
+	if n > 0 {
+		panic("Commit called with more indexes than were reserved.")
+	}
+}
+
+func (z zeroRing) abort() {}
+
+func (z zeroRing) peekN(n uint) (uint, uint, error) {
+	if n > 0 {
+		return 0, 0, ErrEmpty
+	}
+	return 0, 0, nil
+}
+
+func (z zeroRing) release(n uint) {
+	if n > 0 {
+		panic("Release called with more indexes than were peeked.")
+	}
+}
+
+func (z zeroRing) outstanding() (uint, uint) {
+	return 0, 0
+}
+
+func (z zeroRing) generation() uint {
+	return 0
+}
+
+func (z zeroRing) statistics() *Stats {
+	return nil
+}
+
+func (z zeroRing) setStatistics(*Stats) {}
+
 var _ ringBackend = zeroRing{}
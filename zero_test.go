@@ -46,6 +46,29 @@ func TestZeroShift(t *testing.T) {
 	assert.Equal(t, uint(0), i)
 }
 
+func TestZeroUnshiftPop(t *testing.T) {
+	r := newRing()
+	_, err := r.Unshift()
+	assert.Equal(t, o.ErrFull, err)
+	_, err = r.Pop()
+	assert.Equal(t, o.ErrEmpty, err)
+}
+
+func TestZeroReserve(t *testing.T) {
+	r := newRing()
+	_, _, err := r.Reserve(1)
+	assert.Equal(t, o.ErrFull, err)
+	assert.Panics(t, func() { r.Commit(1) })
+	r.Abort()
+}
+
+func TestZeroPeek(t *testing.T) {
+	r := newRing()
+	_, _, err := r.PeekN(1)
+	assert.Equal(t, o.ErrEmpty, err)
+	assert.Panics(t, func() { r.Release(1) })
+}
+
 func BenchmarkZeroRing(b *testing.B) {
 	r := newRing()
 	var i uint